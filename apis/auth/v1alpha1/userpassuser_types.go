@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// UserpassUserParameters are the configurable fields of a UserpassUser.
+type UserpassUserParameters struct {
+	// Path the userpass auth method is mounted at.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=userpass
+	Mount string `json:"mount,omitempty"`
+
+	// Name of the user.
+	// +kubebuilder:validation:Required
+	Username string `json:"username"`
+
+	// Reference to the password of the user. A password is generated and
+	// written to the connection secret if omitted.
+	// +kubebuilder:validation:Optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// List of CIDR blocks; if set, specifies blocks of IP addresses which can
+	// authenticate successfully, and ties the resulting token to these blocks
+	// as well.
+	// +kubebuilder:validation:Optional
+	TokenBoundCidrs []string `json:"tokenBoundCidrs,omitempty"`
+
+	// Generated Token's Explicit Maximum TTL in seconds
+	// +kubebuilder:validation:Optional
+	TokenExplicitMaxTTL *int64 `json:"tokenExplicitMaxTtl,omitempty"`
+
+	// The maximum lifetime of the generated token
+	// +kubebuilder:validation:Optional
+	TokenMaxTTL *int64 `json:"tokenMaxTtl,omitempty"`
+
+	// If true, the 'default' policy will not automatically be added to
+	// generated tokens
+	// +kubebuilder:validation:Optional
+	TokenNoDefaultPolicy *bool `json:"tokenNoDefaultPolicy,omitempty"`
+
+	// The maximum number of times a token may be used, a value of zero means
+	// unlimited
+	// +kubebuilder:validation:Optional
+	TokenNumUses *int64 `json:"tokenNumUses,omitempty"`
+
+	// Generated Token's Period
+	// +kubebuilder:validation:Optional
+	TokenPeriod *int64 `json:"tokenPeriod,omitempty"`
+
+	// Generated Token's Policies
+	// +kubebuilder:validation:Optional
+	TokenPolicies []string `json:"tokenPolicies,omitempty"`
+
+	// The initial ttl of the token to generate in seconds
+	// +kubebuilder:validation:Optional
+	TokenTTL *int64 `json:"tokenTtl,omitempty"`
+
+	// The type of token to generate, service or batch
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=default;service;batch;default-service;default-batch
+	TokenType *string `json:"tokenType,omitempty"`
+}
+
+// UserpassUserObservation are the observable fields of a UserpassUser.
+type UserpassUserObservation struct {
+	// Resource version of the referenced password secret the password was
+	// last written from. It is used to detect password changes since Vault
+	// does not return the password hash.
+	PasswordSecretVersion string `json:"passwordSecretVersion,omitempty"`
+}
+
+// A UserpassUserSpec defines the desired state of a UserpassUser.
+type UserpassUserSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       UserpassUserParameters `json:"forProvider"`
+}
+
+// A UserpassUserStatus represents the observed state of a UserpassUser.
+type UserpassUserStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          UserpassUserObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A UserpassUser is a user of a userpass auth method. The username and the
+// password are written to the connection secret under the "username" and
+// "password" keys.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="USERNAME",type="string",JSONPath=".spec.forProvider.username"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type UserpassUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserpassUserSpec   `json:"spec"`
+	Status UserpassUserStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UserpassUserList contains a list of UserpassUser.
+type UserpassUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UserpassUser `json:"items"`
+}
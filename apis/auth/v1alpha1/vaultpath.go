@@ -0,0 +1,34 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "fmt"
+
+const (
+	defaultUserpassMount = "userpass"
+
+	fmtUserpassUserPath = "auth/%s/users/%s"
+)
+
+// GetVaultPath returns the Vault API path of this UserpassUser.
+func (mg *UserpassUser) GetVaultPath() string {
+	mount := mg.Spec.ForProvider.Mount
+	if mount == "" {
+		mount = defaultUserpassMount
+	}
+	return fmt.Sprintf(fmtUserpassUserPath, mount, mg.Spec.ForProvider.Username)
+}
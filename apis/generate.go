@@ -29,12 +29,21 @@ limitations under the License.
 //go:generate bash -c "find ../internal/controller -iname 'zz_*' -delete"
 //go:generate bash -c "find ../internal/controller -type d -empty -delete"
 
+// The generators only include the resource families selected by
+// FAMILY_TAGS, e.g. ",family_kv,family_auth". See config/family.
+
+// Run the generator of the resources built from the Vault OpenAPI document
+//go:generate go run -tags generate${FAMILY_TAGS} ../cmd/openapigen/main.go ..
+
 // Run Terrajet generator
-//go:generate go run -tags generate ../cmd/generator/main.go .. "${TERRAFORM_PROVIDER_SOURCE}"
+//go:generate go run -tags generate${FAMILY_TAGS} ../cmd/generator/main.go .. "${TERRAFORM_PROVIDER_SOURCE}"
 
 // Generate deepcopy methodsets and CRD manifests
 //go:generate go run -tags generate sigs.k8s.io/controller-tools/cmd/controller-gen object:headerFile=../hack/boilerplate.go.txt paths=./... crd:allowDangerousTypes=true,crdVersions=v1 output:artifacts:config=../package/crds
 
+// Remove the CRDs of the resource families that are not selected
+//go:generate go run -tags generate${FAMILY_TAGS} ../cmd/familycrds/main.go ../package/crds
+
 // Generate crossplane-runtime methodsets (resource.Claim, etc)
 //go:generate go run -tags generate github.com/crossplane/crossplane-tools/cmd/angryjet generate-methodsets --header-file=../hack/boilerplate.go.txt ./...
 
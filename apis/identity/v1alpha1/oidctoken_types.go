@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// OIDCTokenParameters are the configurable fields of an OIDCToken.
+type OIDCTokenParameters struct {
+	// Name of the identity OIDC role the token is signed for. The entity of
+	// the token used by the ProviderConfig must be allowed to use the role.
+	// +kubebuilder:validation:Required
+	Role string `json:"role"`
+
+	// How long before its expiry the token is replaced with a new one.
+	// Defaults to a third of the lifetime of the token.
+	// +kubebuilder:validation:Optional
+	RefreshBefore *metav1.Duration `json:"refreshBefore,omitempty"`
+}
+
+// OIDCTokenObservation are the observable fields of an OIDCToken.
+type OIDCTokenObservation struct {
+	// Name of the role the current token was signed for.
+	Role string `json:"role,omitempty"`
+
+	// Client ID of the role, which is the audience of the token.
+	ClientID string `json:"clientId,omitempty"`
+
+	// Time the current token was issued at, i.e. its iat claim.
+	IssuedAt *metav1.Time `json:"issuedAt,omitempty"`
+
+	// Time the current token expires at, i.e. its exp claim.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// An OIDCTokenSpec defines the desired state of an OIDCToken.
+type OIDCTokenSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       OIDCTokenParameters `json:"forProvider"`
+}
+
+// An OIDCTokenStatus represents the observed state of an OIDCToken.
+type OIDCTokenStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          OIDCTokenObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An OIDCToken is a signed identity token of a Vault OIDC role. The token is
+// written to the connection secret under the "token" key and is replaced
+// before it expires.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ROLE",type="string",JSONPath=".spec.forProvider.role"
+// +kubebuilder:printcolumn:name="EXPIRES-AT",type="string",JSONPath=".status.atProvider.expiresAt"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type OIDCToken struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OIDCTokenSpec   `json:"spec"`
+	Status OIDCTokenStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OIDCTokenList contains a list of OIDCToken.
+type OIDCTokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OIDCToken `json:"items"`
+}
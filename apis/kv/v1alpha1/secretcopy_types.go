@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Modes of a SecretCopy.
+const (
+	// SecretCopyModeOnce copies every secret once. Later changes of the
+	// source secrets are not copied.
+	SecretCopyModeOnce = "Once"
+
+	// SecretCopyModeContinuous keeps the copies in sync with their source
+	// secrets, and deletes the copies of the source secrets that are
+	// deleted.
+	SecretCopyModeContinuous = "Continuous"
+)
+
+// Results of copying a secret.
+const (
+	// SecretCopyResultCopied means the secret was written to the
+	// destination.
+	SecretCopyResultCopied = "Copied"
+
+	// SecretCopyResultInSync means the destination already held the secret.
+	SecretCopyResultInSync = "InSync"
+
+	// SecretCopyResultFailed means the secret could not be copied.
+	SecretCopyResultFailed = "Failed"
+
+	// SecretCopyResultConflict means the destination held a different
+	// secret that was not written by the SecretCopy and is not overwritten.
+	SecretCopyResultConflict = "Conflict"
+)
+
+// A SecretCopyLocation is a prefix of a KV secrets engine.
+type SecretCopyLocation struct {
+	// Path the KV secrets engine is mounted at, e.g. secret.
+	// +kubebuilder:validation:Required
+	Mount string `json:"mount"`
+
+	// Prefix of the secrets in the mount, e.g. staging. The whole mount is
+	// used if it is empty.
+	// +kubebuilder:validation:Optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Version of the KV secrets engine.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=1;2
+	// +kubebuilder:default=2
+	KVVersion *int64 `json:"kvVersion,omitempty"`
+
+	// Namespace the secrets engine is mounted in. Vault Enterprise only.
+	// Defaults to the namespace of the ProviderConfig.
+	// +kubebuilder:validation:Optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// Reference to the ProviderConfig of the Vault the secrets engine is
+	// in. Defaults to the ProviderConfig of the SecretCopy. The usage of the
+	// referenced ProviderConfig is not tracked.
+	// +kubebuilder:validation:Optional
+	ProviderConfigReference *xpv1.Reference `json:"providerConfigRef,omitempty"`
+}
+
+// A SecretCopyOverride sets keys of a copied secret.
+type SecretCopyOverride struct {
+	// Path of the secret relative to the prefix, e.g. db/credentials.
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Reference to a Secret whose keys and values are set in the copy,
+	// replacing the ones of the source secret.
+	// +kubebuilder:validation:Required
+	ValuesSecretRef xpv1.SecretReference `json:"valuesSecretRef"`
+}
+
+// SecretCopyParameters are the configurable fields of a SecretCopy.
+type SecretCopyParameters struct {
+	// Source prefix the secrets are copied from.
+	// +kubebuilder:validation:Required
+	Source SecretCopyLocation `json:"source"`
+
+	// Destination prefix the secrets are copied to.
+	// +kubebuilder:validation:Required
+	Destination SecretCopyLocation `json:"destination"`
+
+	// Glob patterns of the paths relative to the source prefix to copy,
+	// e.g. app/*. A pattern that matches a path also matches the paths
+	// below it. Every secret is copied if it is empty.
+	// +kubebuilder:validation:Optional
+	Include []string `json:"include,omitempty"`
+
+	// Glob patterns of the paths relative to the source prefix not to copy.
+	// They take precedence over Include.
+	// +kubebuilder:validation:Optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Keys set in the copies of the secrets, e.g. the credentials of the
+	// environment the secrets are copied for.
+	// +kubebuilder:validation:Optional
+	Overrides []SecretCopyOverride `json:"overrides,omitempty"`
+
+	// Whether the secrets are copied once or kept in sync.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Once;Continuous
+	// +kubebuilder:default=Once
+	Mode *string `json:"mode,omitempty"`
+
+	// Whether secrets at the destination that differ from their source
+	// secrets and were not written by the SecretCopy are overwritten. They
+	// are reported as Conflict and left as they are otherwise. Overwritten
+	// secrets are deleted with the SecretCopy.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	Overwrite *bool `json:"overwrite,omitempty"`
+}
+
+// A SecretCopyPathResult is the result of the last copy of a secret.
+type SecretCopyPathResult struct {
+	// Path of the secret relative to the prefixes.
+	Path string `json:"path"`
+
+	// Result of the last copy, either Copied, InSync, Conflict or Failed.
+	// Only Copied secrets were written by the SecretCopy.
+	Result string `json:"result"`
+
+	// Message of the error the copy failed with.
+	Message string `json:"message,omitempty"`
+
+	// Version of the source secret that was copied. KV version 2 only.
+	SourceVersion *int64 `json:"sourceVersion,omitempty"`
+
+	// Version of the copy the SecretCopy wrote. KV version 2 only.
+	DestinationVersion *int64 `json:"destinationVersion,omitempty"`
+}
+
+// SecretCopyObservation are the observable fields of a SecretCopy.
+type SecretCopyObservation struct {
+	// Results of the secrets that are copied, sorted by path.
+	Paths []SecretCopyPathResult `json:"paths,omitempty"`
+
+	// Time the secrets were last copied at.
+	LastCopyTime *metav1.Time `json:"lastCopyTime,omitempty"`
+}
+
+// A SecretCopySpec defines the desired state of a SecretCopy.
+type SecretCopySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       SecretCopyParameters `json:"forProvider"`
+}
+
+// A SecretCopyStatus represents the observed state of a SecretCopy.
+type SecretCopyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          SecretCopyObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A SecretCopy copies the secrets under a prefix of a KV secrets engine to
+// another prefix, possibly of another mount, namespace or Vault. The copies
+// it wrote are deleted with it.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.mode"
+// +kubebuilder:printcolumn:name="LAST-COPY",type="date",JSONPath=".status.atProvider.lastCopyTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type SecretCopy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SecretCopySpec   `json:"spec"`
+	Status SecretCopyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SecretCopyList contains a list of SecretCopy.
+type SecretCopyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SecretCopy `json:"items"`
+}
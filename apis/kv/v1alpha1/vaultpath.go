@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "strings"
+
+const (
+	kvVersion1       = 1
+	kvDefaultVersion = 2
+
+	kindData = "data"
+)
+
+// GetKVVersion returns the version of the KV secrets engine of this
+// location.
+func (l *SecretCopyLocation) GetKVVersion() int64 {
+	if l.KVVersion == nil {
+		return kvDefaultVersion
+	}
+	return *l.KVVersion
+}
+
+// GetKVPath returns the API path of the supplied kind, i.e. data or
+// metadata, of the supplied path relative to the prefix of this location.
+// KV version 1 has no kinds.
+func (l *SecretCopyLocation) GetKVPath(kind, rel string) string {
+	parts := []string{strings.Trim(l.Mount, "/")}
+	if l.GetKVVersion() != kvVersion1 {
+		parts = append(parts, kind)
+	}
+	for _, p := range []string{l.Prefix, rel} {
+		if p = strings.Trim(p, "/"); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// IsWrittenTo returns true if this result is of a copy that was written to
+// the supplied location. Copies in KV version 2 also need the version that
+// was written.
+func (r *SecretCopyPathResult) IsWrittenTo(l *SecretCopyLocation) bool {
+	return r.Result == SecretCopyResultCopied && (r.DestinationVersion != nil || l.GetKVVersion() == kvVersion1)
+}
+
+// GetVaultPath returns the Vault API path of the destination prefix of this
+// SecretCopy. The paths of the copies it wrote are returned by
+// GetVaultPaths.
+func (mg *SecretCopy) GetVaultPath() string {
+	return mg.Spec.ForProvider.Destination.GetKVPath(kindData, "")
+}
+
+// GetVaultPaths returns the Vault API paths of the copies this SecretCopy
+// wrote.
+func (mg *SecretCopy) GetVaultPaths() []string {
+	dst := &mg.Spec.ForProvider.Destination
+	paths := make([]string, 0, len(mg.Status.AtProvider.Paths))
+	for i := range mg.Status.AtProvider.Paths {
+		if r := &mg.Status.AtProvider.Paths[i]; r.IsWrittenTo(dst) {
+			paths = append(paths, dst.GetKVPath(kindData, r.Path))
+		}
+	}
+	return paths
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TypeSecretSynced is the type of the condition that reports the sync
+// status of the associated secret. Its reason is the sync status reported by
+// Vault, e.g. Synced or ExternalServiceError.
+const TypeSecretSynced xpv1.ConditionType = "SecretSynced"
+
+// AssociationParameters are the configurable fields of an Association. They
+// cannot be changed once the association is created.
+type AssociationParameters struct {
+	// Type of the destination the secret is synced to.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=aws-sm;azure-kv;gcp-sm;gh
+	DestinationType string `json:"destinationType"`
+
+	// Name of the destination the secret is synced to.
+	// +kubebuilder:validation:Required
+	DestinationName string `json:"destinationName"`
+
+	// Path of the KV v2 secrets engine the secret is stored in.
+	// +kubebuilder:validation:Required
+	Mount string `json:"mount"`
+
+	// Path of the secret relative to the mount.
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+}
+
+// AssociationObservation are the observable fields of an Association.
+type AssociationObservation struct {
+	// Accessor of the mount the secret is stored in.
+	Accessor string `json:"accessor,omitempty"`
+
+	// Sync status of the secret reported by Vault.
+	SyncStatus string `json:"syncStatus,omitempty"`
+
+	// Time the sync status was last updated at.
+	UpdatedAt string `json:"updatedAt,omitempty"`
+}
+
+// An AssociationSpec defines the desired state of an Association.
+type AssociationSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       AssociationParameters `json:"forProvider"`
+}
+
+// An AssociationStatus represents the observed state of an Association.
+type AssociationStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AssociationObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An Association makes Vault sync a KV secret to a destination.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SYNC-STATUS",type="string",JSONPath=".status.atProvider.syncStatus"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type Association struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AssociationSpec   `json:"spec"`
+	Status AssociationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AssociationList contains a list of Association.
+type AssociationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Association `json:"items"`
+}
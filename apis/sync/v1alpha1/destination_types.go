@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// DestinationParameters are the configurable fields of a Destination.
+type DestinationParameters struct {
+	// Type of the destination.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=aws-sm;azure-kv;gcp-sm;gh
+	Type string `json:"type"`
+
+	// Name of the destination.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Reference to a secret whose keys are the sensitive connection
+	// parameters of the destination, e.g. access_key_id and
+	// secret_access_key for aws-sm, client_secret for azure-kv, credentials
+	// for gcp-sm and access_token for gh.
+	// +kubebuilder:validation:Optional
+	CredentialsSecretRef *xpv1.SecretReference `json:"credentialsSecretRef,omitempty"`
+
+	// Non-sensitive connection parameters of the destination, e.g. region
+	// for aws-sm, key_vault_uri, tenant_id and client_id for azure-kv,
+	// project_id for gcp-sm, repository_owner and repository_name for gh.
+	// +kubebuilder:validation:Optional
+	ConnectionParameters map[string]string `json:"connectionParameters,omitempty"`
+
+	// Template of the name of the synced secrets in the destination.
+	// +kubebuilder:validation:Optional
+	SecretNameTemplate *string `json:"secretNameTemplate,omitempty"`
+
+	// Whether a secret is synced as a whole or key by key.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=secret-path;secret-key
+	Granularity *string `json:"granularity,omitempty"`
+
+	// Tags added to the synced secrets in the destination. Not supported by
+	// gh destinations.
+	// +kubebuilder:validation:Optional
+	CustomTags map[string]string `json:"customTags,omitempty"`
+}
+
+// DestinationObservation are the observable fields of a Destination.
+type DestinationObservation struct {
+	// Resource version of the credentials secret the credentials were last
+	// written from. It is used to detect credential changes since Vault does
+	// not return them.
+	CredentialsSecretVersion string `json:"credentialsSecretVersion,omitempty"`
+}
+
+// A DestinationSpec defines the desired state of a Destination.
+type DestinationSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       DestinationParameters `json:"forProvider"`
+}
+
+// A DestinationStatus represents the observed state of a Destination.
+type DestinationStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          DestinationObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Destination is an external secret store that Vault secrets sync pushes
+// KV secrets to.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.forProvider.type"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type Destination struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DestinationSpec   `json:"spec"`
+	Status DestinationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// DestinationList contains a list of Destination.
+type DestinationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Destination `json:"items"`
+}
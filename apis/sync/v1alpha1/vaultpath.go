@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "fmt"
+
+const (
+	fmtDestinationPath  = "sys/sync/destinations/%s/%s"
+	fmtAssociationsPath = fmtDestinationPath + "/associations"
+)
+
+// GetVaultPath returns the Vault API path of this Destination.
+func (mg *Destination) GetVaultPath() string {
+	return fmt.Sprintf(fmtDestinationPath, mg.Spec.ForProvider.Type, mg.Spec.ForProvider.Name)
+}
+
+// GetVaultPath returns the Vault API path of the associations of the
+// destination of this Association.
+func (mg *Association) GetVaultPath() string {
+	return fmt.Sprintf(fmtAssociationsPath, mg.Spec.ForProvider.DestinationType, mg.Spec.ForProvider.DestinationName)
+}
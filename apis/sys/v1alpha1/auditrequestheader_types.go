@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AuditRequestHeaderParameters are the configurable fields of an
+// AuditRequestHeader.
+type AuditRequestHeaderParameters struct {
+	// Name of the request header. Header names are case insensitive.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Whether the value of the header is HMAC'd in audit logs.
+	// +kubebuilder:validation:Optional
+	HMAC *bool `json:"hmac,omitempty"`
+}
+
+// An AuditRequestHeaderSpec defines the desired state of an
+// AuditRequestHeader.
+type AuditRequestHeaderSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       AuditRequestHeaderParameters `json:"forProvider"`
+}
+
+// An AuditRequestHeaderStatus represents the observed state of an
+// AuditRequestHeader.
+type AuditRequestHeaderStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AuditRequestHeader is a request header that audit devices log.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="HEADER",type="string",JSONPath=".spec.forProvider.name"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type AuditRequestHeader struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AuditRequestHeaderSpec   `json:"spec"`
+	Status AuditRequestHeaderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AuditRequestHeaderList contains a list of AuditRequestHeader.
+type AuditRequestHeaderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuditRequestHeader `json:"items"`
+}
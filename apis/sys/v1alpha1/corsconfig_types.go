@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// CORSConfigParameters are the configurable fields of a CORSConfig.
+type CORSConfigParameters struct {
+	// Origins allowed to make cross-origin requests, or * to allow all.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	AllowedOrigins []string `json:"allowedOrigins"`
+
+	// Headers allowed in cross-origin requests in addition to the ones Vault
+	// always allows.
+	// +kubebuilder:validation:Optional
+	AllowedHeaders []string `json:"allowedHeaders,omitempty"`
+}
+
+// A CORSConfigSpec defines the desired state of a CORSConfig.
+type CORSConfigSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       CORSConfigParameters `json:"forProvider"`
+}
+
+// A CORSConfigStatus represents the observed state of a CORSConfig.
+type CORSConfigStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A CORSConfig enables and configures cross-origin resource sharing of the
+// Vault API. There is a single CORS configuration per cluster, so there
+// should be at most one CORSConfig per ProviderConfig. Deleting it disables
+// CORS.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type CORSConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CORSConfigSpec   `json:"spec"`
+	Status CORSConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CORSConfigList contains a list of CORSConfig.
+type CORSConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CORSConfig `json:"items"`
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A ManagedScopePrefix is a set of Vault objects that are meant to be backed
+// by managed resources.
+type ManagedScopePrefix struct {
+	// API path of the objects. Its last segment may be a glob pattern, e.g.
+	// secret/metadata/apps/*, auth/kubernetes/role/* or sys/policy/team-*.
+	// The objects below the directories that match are included too. KV
+	// version 2 secrets are matched by their metadata path, so that pruning
+	// deletes all of their versions, and are owned by the managed resources
+	// that write to their data path.
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// API path the objects are listed at if it is not the directory of
+	// Path.
+	// +kubebuilder:validation:Optional
+	ListPath *string `json:"listPath,omitempty"`
+}
+
+// ManagedScopeParameters are the configurable fields of a ManagedScope.
+type ManagedScopeParameters struct {
+	// Prefixes of the objects that are meant to be backed by managed
+	// resources with the same ProviderConfig.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Prefixes []ManagedScopePrefix `json:"prefixes"`
+
+	// Whether objects that are not backed by a managed resource are deleted
+	// once their grace period has passed.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	Prune *bool `json:"prune,omitempty"`
+
+	// Time an object has to be found not backed by a managed resource for
+	// before it is deleted.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="24h"
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+}
+
+// A ManagedScopeObject is a Vault object that is not backed by a managed
+// resource.
+type ManagedScopeObject struct {
+	// API path of the object.
+	Path string `json:"path"`
+
+	// Time the object was first found not backed by a managed resource.
+	FirstSeen metav1.Time `json:"firstSeen"`
+
+	// Time after which the object is deleted if pruning is enabled.
+	PruneAfter *metav1.Time `json:"pruneAfter,omitempty"`
+}
+
+// ManagedScopeObservation are the observable fields of a ManagedScope.
+type ManagedScopeObservation struct {
+	// Objects under the prefixes that are not backed by a managed resource,
+	// sorted by path.
+	Unowned []ManagedScopeObject `json:"unowned,omitempty"`
+
+	// Number of objects under the prefixes that are not backed by a managed
+	// resource.
+	UnownedObjects int64 `json:"unownedObjects,omitempty"`
+
+	// Number of objects under the prefixes that are backed by a managed
+	// resource.
+	OwnedObjects int64 `json:"ownedObjects,omitempty"`
+
+	// Number of objects that were deleted because they were not backed by a
+	// managed resource.
+	PrunedObjects int64 `json:"prunedObjects,omitempty"`
+
+	// Managed resources, as kind/name, whose Vault paths are not known. No
+	// object is deleted while there are any, since it may belong to one of
+	// them.
+	UnresolvedResources []string `json:"unresolvedResources,omitempty"`
+
+	// Time the prefixes were last listed at.
+	LastScanTime *metav1.Time `json:"lastScanTime,omitempty"`
+}
+
+// A ManagedScopeSpec defines the desired state of a ManagedScope.
+type ManagedScopeSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ManagedScopeParameters `json:"forProvider"`
+}
+
+// A ManagedScopeStatus represents the observed state of a ManagedScope.
+type ManagedScopeStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ManagedScopeObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ManagedScope reports the Vault objects under prefixes that are meant to
+// be managed by Crossplane but are not backed by any managed resource, and
+// optionally deletes them. Deleting a ManagedScope deletes no Vault objects.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="UNOWNED",type="integer",JSONPath=".status.atProvider.unownedObjects"
+// +kubebuilder:printcolumn:name="PRUNE",type="boolean",JSONPath=".spec.forProvider.prune"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type ManagedScope struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedScopeSpec   `json:"spec"`
+	Status ManagedScopeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ManagedScopeList contains a list of ManagedScope.
+type ManagedScopeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ManagedScope `json:"items"`
+}
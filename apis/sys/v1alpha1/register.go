@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+// Package type metadata.
+const (
+	Group   = "sys.vault.jet.crossplane.io"
+	Version = "v1alpha1"
+)
+
+var (
+	// SchemeGroupVersion is group version used to register these objects
+	SchemeGroupVersion = schema.GroupVersion{Group: Group, Version: Version}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: SchemeGroupVersion}
+)
+
+// ReplicationPrimary type metadata.
+var (
+	ReplicationPrimaryKind             = reflect.TypeOf(ReplicationPrimary{}).Name()
+	ReplicationPrimaryGroupKind        = schema.GroupKind{Group: Group, Kind: ReplicationPrimaryKind}.String()
+	ReplicationPrimaryKindAPIVersion   = ReplicationPrimaryKind + "." + SchemeGroupVersion.String()
+	ReplicationPrimaryGroupVersionKind = SchemeGroupVersion.WithKind(ReplicationPrimaryKind)
+)
+
+// ReplicationSecondaryToken type metadata.
+var (
+	ReplicationSecondaryTokenKind             = reflect.TypeOf(ReplicationSecondaryToken{}).Name()
+	ReplicationSecondaryTokenGroupKind        = schema.GroupKind{Group: Group, Kind: ReplicationSecondaryTokenKind}.String()
+	ReplicationSecondaryTokenKindAPIVersion   = ReplicationSecondaryTokenKind + "." + SchemeGroupVersion.String()
+	ReplicationSecondaryTokenGroupVersionKind = SchemeGroupVersion.WithKind(ReplicationSecondaryTokenKind)
+)
+
+// ReplicationSecondary type metadata.
+var (
+	ReplicationSecondaryKind             = reflect.TypeOf(ReplicationSecondary{}).Name()
+	ReplicationSecondaryGroupKind        = schema.GroupKind{Group: Group, Kind: ReplicationSecondaryKind}.String()
+	ReplicationSecondaryKindAPIVersion   = ReplicationSecondaryKind + "." + SchemeGroupVersion.String()
+	ReplicationSecondaryGroupVersionKind = SchemeGroupVersion.WithKind(ReplicationSecondaryKind)
+)
+
+// ReplicationPathsFilter type metadata.
+var (
+	ReplicationPathsFilterKind             = reflect.TypeOf(ReplicationPathsFilter{}).Name()
+	ReplicationPathsFilterGroupKind        = schema.GroupKind{Group: Group, Kind: ReplicationPathsFilterKind}.String()
+	ReplicationPathsFilterKindAPIVersion   = ReplicationPathsFilterKind + "." + SchemeGroupVersion.String()
+	ReplicationPathsFilterGroupVersionKind = SchemeGroupVersion.WithKind(ReplicationPathsFilterKind)
+)
+
+// CORSConfig type metadata.
+var (
+	CORSConfigKind             = reflect.TypeOf(CORSConfig{}).Name()
+	CORSConfigGroupKind        = schema.GroupKind{Group: Group, Kind: CORSConfigKind}.String()
+	CORSConfigKindAPIVersion   = CORSConfigKind + "." + SchemeGroupVersion.String()
+	CORSConfigGroupVersionKind = SchemeGroupVersion.WithKind(CORSConfigKind)
+)
+
+// UIHeader type metadata.
+var (
+	UIHeaderKind             = reflect.TypeOf(UIHeader{}).Name()
+	UIHeaderGroupKind        = schema.GroupKind{Group: Group, Kind: UIHeaderKind}.String()
+	UIHeaderKindAPIVersion   = UIHeaderKind + "." + SchemeGroupVersion.String()
+	UIHeaderGroupVersionKind = SchemeGroupVersion.WithKind(UIHeaderKind)
+)
+
+// AuditRequestHeader type metadata.
+var (
+	AuditRequestHeaderKind             = reflect.TypeOf(AuditRequestHeader{}).Name()
+	AuditRequestHeaderGroupKind        = schema.GroupKind{Group: Group, Kind: AuditRequestHeaderKind}.String()
+	AuditRequestHeaderKindAPIVersion   = AuditRequestHeaderKind + "." + SchemeGroupVersion.String()
+	AuditRequestHeaderGroupVersionKind = SchemeGroupVersion.WithKind(AuditRequestHeaderKind)
+)
+
+// ManagedScope type metadata.
+var (
+	ManagedScopeKind             = reflect.TypeOf(ManagedScope{}).Name()
+	ManagedScopeGroupKind        = schema.GroupKind{Group: Group, Kind: ManagedScopeKind}.String()
+	ManagedScopeKindAPIVersion   = ManagedScopeKind + "." + SchemeGroupVersion.String()
+	ManagedScopeGroupVersionKind = SchemeGroupVersion.WithKind(ManagedScopeKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&ReplicationPrimary{}, &ReplicationPrimaryList{})
+	SchemeBuilder.Register(&ReplicationSecondaryToken{}, &ReplicationSecondaryTokenList{})
+	SchemeBuilder.Register(&ReplicationSecondary{}, &ReplicationSecondaryList{})
+	SchemeBuilder.Register(&ReplicationPathsFilter{}, &ReplicationPathsFilterList{})
+	SchemeBuilder.Register(&CORSConfig{}, &CORSConfigList{})
+	SchemeBuilder.Register(&UIHeader{}, &UIHeaderList{})
+	SchemeBuilder.Register(&AuditRequestHeader{}, &AuditRequestHeaderList{})
+	SchemeBuilder.Register(&ManagedScope{}, &ManagedScopeList{})
+}
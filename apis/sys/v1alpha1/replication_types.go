@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Replication modes.
+const (
+	ReplicationModePerformance = "performance"
+	ReplicationModeDR          = "dr"
+)
+
+// ReplicationStatus is the replication status of a cluster as reported by
+// Vault for a replication mode.
+type ReplicationStatus struct {
+	// Replication role of the cluster, i.e. primary, secondary or disabled.
+	Mode string `json:"mode,omitempty"`
+
+	// Replication state of the cluster, e.g. running or stream-wals.
+	State string `json:"state,omitempty"`
+
+	// State of the connection of a secondary to its primary.
+	ConnectionState string `json:"connectionState,omitempty"`
+
+	// ID of the replication set the cluster belongs to.
+	ClusterID string `json:"clusterId,omitempty"`
+
+	// Cluster address of the primary.
+	PrimaryClusterAddr string `json:"primaryClusterAddr,omitempty"`
+
+	// ID of a secondary cluster.
+	SecondaryID string `json:"secondaryId,omitempty"`
+
+	// IDs of the secondaries known to a primary.
+	KnownSecondaries []string `json:"knownSecondaries,omitempty"`
+
+	// Index of the last WAL entry written locally.
+	LastWAL int64 `json:"lastWal,omitempty"`
+
+	// Index of the last WAL entry streamed to a performance secondary.
+	LastPerformanceWAL int64 `json:"lastPerformanceWal,omitempty"`
+
+	// Index of the last WAL entry streamed to a DR secondary.
+	LastDRWAL int64 `json:"lastDrWal,omitempty"`
+
+	// Index of the last WAL entry a secondary received from its primary.
+	LastRemoteWAL int64 `json:"lastRemoteWal,omitempty"`
+
+	// Merkle root of the replicated data.
+	MerkleRoot string `json:"merkleRoot,omitempty"`
+}
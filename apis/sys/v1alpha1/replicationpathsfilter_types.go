@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ReplicationPathsFilterParameters are the configurable fields of a
+// ReplicationPathsFilter.
+type ReplicationPathsFilterParameters struct {
+	// ID of the performance secondary the filter applies to.
+	// +kubebuilder:validation:Required
+	SecondaryID string `json:"secondaryId"`
+
+	// Whether the paths are the only ones replicated to the secondary or the
+	// ones that are not.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=allow;deny
+	Mode string `json:"mode"`
+
+	// Mount paths and namespaces the filter applies to.
+	// +kubebuilder:validation:Required
+	Paths []string `json:"paths"`
+}
+
+// A ReplicationPathsFilterSpec defines the desired state of a
+// ReplicationPathsFilter.
+type ReplicationPathsFilterSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ReplicationPathsFilterParameters `json:"forProvider"`
+}
+
+// A ReplicationPathsFilterStatus represents the observed state of a
+// ReplicationPathsFilter.
+type ReplicationPathsFilterStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ReplicationPathsFilter restricts the paths a performance primary
+// replicates to a secondary. Vault Enterprise only.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SECONDARY",type="string",JSONPath=".spec.forProvider.secondaryId"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.mode"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type ReplicationPathsFilter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReplicationPathsFilterSpec   `json:"spec"`
+	Status ReplicationPathsFilterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplicationPathsFilterList contains a list of ReplicationPathsFilter.
+type ReplicationPathsFilterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicationPathsFilter `json:"items"`
+}
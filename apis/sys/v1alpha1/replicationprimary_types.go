@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ReplicationPrimaryParameters are the configurable fields of a
+// ReplicationPrimary.
+type ReplicationPrimaryParameters struct {
+	// Replication mode the cluster is the primary of.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=performance;dr
+	Mode string `json:"mode"`
+
+	// Cluster address secondaries connect to. Defaults to the cluster address
+	// of the active node.
+	// +kubebuilder:validation:Optional
+	PrimaryClusterAddr *string `json:"primaryClusterAddr,omitempty"`
+}
+
+// A ReplicationPrimarySpec defines the desired state of a ReplicationPrimary.
+type ReplicationPrimarySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ReplicationPrimaryParameters `json:"forProvider"`
+}
+
+// A ReplicationPrimaryStatus represents the observed state of a
+// ReplicationPrimary.
+type ReplicationPrimaryStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ReplicationStatus `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ReplicationPrimary enables performance or DR replication on a cluster as
+// the primary. Vault Enterprise only.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.mode"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.state"
+// +kubebuilder:printcolumn:name="LAST-WAL",type="integer",JSONPath=".status.atProvider.lastWal"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type ReplicationPrimary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReplicationPrimarySpec   `json:"spec"`
+	Status ReplicationPrimaryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplicationPrimaryList contains a list of ReplicationPrimary.
+type ReplicationPrimaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicationPrimary `json:"items"`
+}
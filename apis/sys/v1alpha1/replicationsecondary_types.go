@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ReplicationSecondaryParameters are the configurable fields of a
+// ReplicationSecondary.
+type ReplicationSecondaryParameters struct {
+	// Replication mode the cluster is a secondary of.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=performance;dr
+	Mode string `json:"mode"`
+
+	// Reference to the ReplicationSecondaryToken whose connection secret
+	// holds the activation token. The token is usually generated through the
+	// ProviderConfig of the primary cluster while this resource uses the one
+	// of the secondary.
+	// +kubebuilder:validation:Optional
+	SecondaryTokenRef *xpv1.Reference `json:"secondaryTokenRef,omitempty"`
+
+	// Reference to a secret key holding the activation token. Takes
+	// precedence over SecondaryTokenRef.
+	// +kubebuilder:validation:Optional
+	TokenSecretRef *xpv1.SecretKeySelector `json:"tokenSecretRef,omitempty"`
+
+	// API address of the primary. Defaults to the one in the activation
+	// token.
+	// +kubebuilder:validation:Optional
+	PrimaryAPIAddr *string `json:"primaryApiAddr,omitempty"`
+
+	// Path of a CA certificate file used to verify the primary.
+	// +kubebuilder:validation:Optional
+	CAFile *string `json:"caFile,omitempty"`
+
+	// Path of a directory of CA certificates used to verify the primary.
+	// +kubebuilder:validation:Optional
+	CAPath *string `json:"caPath,omitempty"`
+}
+
+// A ReplicationSecondarySpec defines the desired state of a
+// ReplicationSecondary.
+type ReplicationSecondarySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ReplicationSecondaryParameters `json:"forProvider"`
+}
+
+// A ReplicationSecondaryStatus represents the observed state of a
+// ReplicationSecondary.
+type ReplicationSecondaryStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ReplicationStatus `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ReplicationSecondary activates a cluster as a performance or DR
+// secondary. Activation replaces the data of the cluster with the one of the
+// primary. Vault Enterprise only.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.mode"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.state"
+// +kubebuilder:printcolumn:name="LAST-REMOTE-WAL",type="integer",JSONPath=".status.atProvider.lastRemoteWal"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type ReplicationSecondary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReplicationSecondarySpec   `json:"spec"`
+	Status ReplicationSecondaryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplicationSecondaryList contains a list of ReplicationSecondary.
+type ReplicationSecondaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicationSecondary `json:"items"`
+}
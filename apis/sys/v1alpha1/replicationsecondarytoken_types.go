@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ReplicationSecondaryTokenKey is the connection secret key the activation
+// token is published under.
+const ReplicationSecondaryTokenKey = "token"
+
+// ReplicationSecondaryTokenParameters are the configurable fields of a
+// ReplicationSecondaryToken.
+type ReplicationSecondaryTokenParameters struct {
+	// Replication mode of the secondary the token activates.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=performance;dr
+	Mode string `json:"mode"`
+
+	// ID the primary identifies the secondary by.
+	// +kubebuilder:validation:Required
+	ID string `json:"id"`
+
+	// Lifetime of the activation token, e.g. 30m.
+	// +kubebuilder:validation:Optional
+	TTL *string `json:"ttl,omitempty"`
+}
+
+// A ReplicationSecondaryTokenSpec defines the desired state of a
+// ReplicationSecondaryToken.
+type ReplicationSecondaryTokenSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ReplicationSecondaryTokenParameters `json:"forProvider"`
+}
+
+// A ReplicationSecondaryTokenStatus represents the observed state of a
+// ReplicationSecondaryToken.
+type ReplicationSecondaryTokenStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ReplicationSecondaryToken registers a secondary with a primary cluster
+// and publishes its activation token under the token key of its connection
+// secret. Deleting it revokes the secondary. Vault Enterprise only.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.mode"
+// +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".spec.forProvider.id"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type ReplicationSecondaryToken struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ReplicationSecondaryTokenSpec   `json:"spec"`
+	Status ReplicationSecondaryTokenStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ReplicationSecondaryTokenList contains a list of ReplicationSecondaryToken.
+type ReplicationSecondaryTokenList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicationSecondaryToken `json:"items"`
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// UIHeaderParameters are the configurable fields of a UIHeader.
+type UIHeaderParameters struct {
+	// Name of the header.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Values of the header.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
+// A UIHeaderSpec defines the desired state of a UIHeader.
+type UIHeaderSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       UIHeaderParameters `json:"forProvider"`
+}
+
+// A UIHeaderStatus represents the observed state of a UIHeader.
+type UIHeaderStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+
+// A UIHeader is a custom header Vault returns with the responses of its web
+// UI.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="HEADER",type="string",JSONPath=".spec.forProvider.name"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,vaultjet}
+type UIHeader struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UIHeaderSpec   `json:"spec"`
+	Status UIHeaderStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// UIHeaderList contains a list of UIHeader.
+type UIHeaderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UIHeader `json:"items"`
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "fmt"
+
+const (
+	pathCORSConfig = "sys/config/cors"
+
+	fmtAuditRequestHeaderPath     = "sys/config/auditing/request-headers/%s"
+	fmtUIHeaderPath               = "sys/config/ui/headers/%s"
+	fmtReplicationPathsFilterPath = "sys/replication/" + ReplicationModePerformance + "/primary/paths-filter/%s"
+)
+
+// GetVaultPath returns the Vault API path of this AuditRequestHeader.
+func (mg *AuditRequestHeader) GetVaultPath() string {
+	return fmt.Sprintf(fmtAuditRequestHeaderPath, mg.Spec.ForProvider.Name)
+}
+
+// GetVaultPath returns the Vault API path of this CORSConfig.
+func (mg *CORSConfig) GetVaultPath() string {
+	return pathCORSConfig
+}
+
+// GetVaultPath returns the Vault API path of this UIHeader.
+func (mg *UIHeader) GetVaultPath() string {
+	return fmt.Sprintf(fmtUIHeaderPath, mg.Spec.ForProvider.Name)
+}
+
+// GetVaultPath returns the Vault API path of this ReplicationPathsFilter.
+// Paths filters only apply to performance replication.
+func (mg *ReplicationPathsFilter) GetVaultPath() string {
+	return fmt.Sprintf(fmtReplicationPathsFilterPath, mg.Spec.ForProvider.SecondaryID)
+}
+
+// GetVaultPath returns the Vault API path of this ReplicationPrimary, which
+// is empty because it configures the replication state of the cluster
+// rather than a Vault object.
+func (mg *ReplicationPrimary) GetVaultPath() string {
+	return ""
+}
+
+// GetVaultPath returns the Vault API path of this ReplicationSecondary,
+// which is empty because it configures the replication state of the cluster
+// rather than a Vault object.
+func (mg *ReplicationSecondary) GetVaultPath() string {
+	return ""
+}
+
+// GetVaultPath returns the Vault API path of this ReplicationSecondaryToken,
+// which is empty because the secondaries a primary knows are no Vault
+// objects.
+func (mg *ReplicationSecondaryToken) GetVaultPath() string {
+	return ""
+}
+
+// GetVaultPath returns the Vault API path of this ManagedScope, which is
+// empty because it writes no Vault object.
+func (mg *ManagedScope) GetVaultPath() string {
+	return ""
+}
@@ -58,7 +58,16 @@ var (
 	ProviderConfigUsageListGroupVersionKind = SchemeGroupVersion.WithKind(ProviderConfigUsageListKind)
 )
 
+// ProviderConfigBinding type metadata.
+var (
+	ProviderConfigBindingKind             = reflect.TypeOf(ProviderConfigBinding{}).Name()
+	ProviderConfigBindingGroupKind        = schema.GroupKind{Group: Group, Kind: ProviderConfigBindingKind}.String()
+	ProviderConfigBindingKindAPIVersion   = ProviderConfigBindingKind + "." + SchemeGroupVersion.String()
+	ProviderConfigBindingGroupVersionKind = SchemeGroupVersion.WithKind(ProviderConfigBindingKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ProviderConfig{}, &ProviderConfigList{})
 	SchemeBuilder.Register(&ProviderConfigUsage{}, &ProviderConfigUsageList{})
+	SchemeBuilder.Register(&ProviderConfigBinding{}, &ProviderConfigBindingList{})
 }
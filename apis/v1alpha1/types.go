@@ -90,3 +90,52 @@ type ProviderConfigUsageList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []ProviderConfigUsage `json:"items"`
 }
+
+// A ProviderConfigBindingSpec defines the managed resources a
+// ProviderConfigBinding selects and the ProviderConfig they are bound to.
+type ProviderConfigBindingSpec struct {
+	// ProviderConfigReference is the ProviderConfig the selected managed
+	// resources are bound to.
+	ProviderConfigReference xpv1.Reference `json:"providerConfigRef"`
+
+	// ResourceSelector selects managed resources by their labels.
+	// +optional
+	ResourceSelector *metav1.LabelSelector `json:"resourceSelector,omitempty"`
+
+	// SecretNamespaces select managed resources that reference a Secret in
+	// one of these namespaces.
+	// +optional
+	SecretNamespaces []string `json:"secretNamespaces,omitempty"`
+
+	// Priority of the binding if several bindings select a managed resource.
+	// The binding with the highest priority wins, and of bindings with the
+	// same priority the one whose name sorts first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ProviderConfigBinding binds the managed resources it selects to a
+// ProviderConfig when they are created without one. Resources are selected
+// by their labels and by the namespaces of the Secrets they reference. A
+// binding without selectors selects every managed resource.
+// +kubebuilder:printcolumn:name="CONFIG-NAME",type="string",JSONPath=".spec.providerConfigRef.name"
+// +kubebuilder:printcolumn:name="PRIORITY",type="integer",JSONPath=".spec.priority"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,vaultjet}
+type ProviderConfigBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProviderConfigBindingSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProviderConfigBindingList contains a list of ProviderConfigBinding.
+type ProviderConfigBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProviderConfigBinding `json:"items"`
+}
@@ -22,7 +22,13 @@ package apis
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 
-	v1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/generic/v1alpha1"
+	v1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/auth/v1alpha1"
+	v1alpha1generic "github.com/crossplane-contrib/provider-jet-vault/apis/generic/v1alpha1"
+	v1alpha1identity "github.com/crossplane-contrib/provider-jet-vault/apis/identity/v1alpha1"
+	v1alpha1kv "github.com/crossplane-contrib/provider-jet-vault/apis/kv/v1alpha1"
+	v1alpha1oidc "github.com/crossplane-contrib/provider-jet-vault/apis/oidc/v1alpha1"
+	v1alpha1sync "github.com/crossplane-contrib/provider-jet-vault/apis/sync/v1alpha1"
+	v1alpha1sys "github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
 	v1alpha1apis "github.com/crossplane-contrib/provider-jet-vault/apis/v1alpha1"
 )
 
@@ -30,6 +36,12 @@ func init() {
 	// Register the types with the Scheme so the components can map objects to GroupVersionKinds and back
 	AddToSchemes = append(AddToSchemes,
 		v1alpha1.SchemeBuilder.AddToScheme,
+		v1alpha1generic.SchemeBuilder.AddToScheme,
+		v1alpha1identity.SchemeBuilder.AddToScheme,
+		v1alpha1kv.SchemeBuilder.AddToScheme,
+		v1alpha1oidc.SchemeBuilder.AddToScheme,
+		v1alpha1sync.SchemeBuilder.AddToScheme,
+		v1alpha1sys.SchemeBuilder.AddToScheme,
 		v1alpha1apis.SchemeBuilder.AddToScheme,
 	)
 }
@@ -0,0 +1,59 @@
+//go:build generate
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// familycrds removes the CRDs of the resource families the provider is not
+// built for from the supplied directory, since controller-gen generates them
+// for every API package in the tree. The CRDs of the ProviderConfig API are
+// removed unless the config family is selected.
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crossplane-contrib/provider-jet-vault/config/family"
+)
+
+const rootGroup = "vault.jet.crossplane.io"
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "" {
+		panic("CRD directory is required to be given as argument")
+	}
+	dir := os.Args[1]
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		panic(fmt.Sprintf("cannot read CRD directory %s: %v", dir, err))
+	}
+	for _, f := range files {
+		// controller-gen names CRD files <group>_<plural>.yaml.
+		group := strings.SplitN(f.Name(), "_", 2)[0]
+		if f.IsDir() || !strings.HasSuffix(group, rootGroup) {
+			continue
+		}
+		if family.IncludesGroup(strings.TrimSuffix(strings.TrimSuffix(group, rootGroup), ".")) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+			panic(fmt.Sprintf("cannot remove CRD %s: %v", f.Name(), err))
+		}
+	}
+}
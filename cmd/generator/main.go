@@ -19,15 +19,30 @@ limitations under the License.
 package main
 
 import (
+	_ "embed" // nolint:golint
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/crossplane/terrajet/pkg/pipeline"
+	"github.com/crossplane/terrajet/pkg/pipeline/templates"
 
 	"github.com/crossplane-contrib/provider-jet-vault/config"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/validation"
 )
 
+// controllerTemplate replaces the controller template of Terrajet so that
+// the generated controllers record their Terraform workspaces.
+//
+//go:embed controller.go.tmpl
+var controllerTemplate string
+
+// setupTemplate replaces the setup template of Terrajet so that the
+// controllers are set up with the options of the provider.
+//
+//go:embed setup.go.tmpl
+var setupTemplate string
+
 func main() {
 	if len(os.Args) < 2 || os.Args[1] == "" {
 		panic("root directory is required to be given as argument")
@@ -36,5 +51,14 @@ func main() {
 	if err != nil {
 		panic(fmt.Sprintf("cannot calculate the absolute path of %s", os.Args[1]))
 	}
-	pipeline.Run(config.GetProvider(), absRootDir)
+	pc := config.GetProvider()
+	templates.ControllerTemplate = controllerTemplate
+	templates.SetupTemplate = setupTemplate
+	pipeline.Run(pc, absRootDir)
+	// Sensitive attributes must end up in Secrets, never in plain fields of
+	// the generated API, so generation fails if any of them leaked.
+	if err := validation.SensitiveFields(pc, absRootDir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
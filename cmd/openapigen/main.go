@@ -0,0 +1,40 @@
+//go:build generate
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/openapi/generator"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "" {
+		panic("root directory is required to be given as argument")
+	}
+	absRootDir, err := filepath.Abs(os.Args[1])
+	if err != nil {
+		panic(fmt.Sprintf("cannot calculate the absolute path of %s", os.Args[1]))
+	}
+	if err := generator.Run(absRootDir); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/backup"
+)
+
+const (
+	errCreateBackup = "cannot create backup file"
+	errOpenBackup   = "cannot open backup file"
+	errBackup       = "cannot back up"
+	errRestore      = "cannot restore"
+)
+
+// runBackup writes an archive of the managed resources of the cluster of the
+// current kubeconfig context, encrypted with age to the supplied recipients,
+// to the supplied file.
+func runBackup(ctx context.Context, output string, age *backup.Age, recipients, recipientsFiles []string) error {
+	kube, err := newClusterClient()
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Clean(output), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, errCreateBackup)
+	}
+	w, err := age.Encrypt(ctx, f, recipients, recipientsFiles)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := backup.Backup(ctx, kube, w); err != nil {
+		_ = w.Close()
+		_ = f.Close()
+		_ = os.Remove(filepath.Clean(output))
+		return errors.Wrap(err, errBackup)
+	}
+	if err := w.Close(); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, errBackup)
+	}
+	return errors.Wrap(f.Close(), errCreateBackup)
+}
+
+// runRestore restores the supplied archive, decrypted with the supplied age
+// identities, into the cluster of the current kubeconfig context and the
+// Vaults of its ProviderConfigs, and prints what it restored.
+func runRestore(ctx context.Context, out io.Writer, input string, age *backup.Age, identities []string) error {
+	kube, err := newClusterClient()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Clean(input))
+	if err != nil {
+		return errors.Wrap(err, errOpenBackup)
+	}
+	defer f.Close() // nolint:errcheck
+	r, err := age.Decrypt(ctx, f, identities)
+	if err != nil {
+		return err
+	}
+	defer r.Close() // nolint:errcheck
+	rep, err := backup.Restore(ctx, kube, r)
+	for _, id := range rep.Created {
+		fmt.Fprintf(out, "created %s\n", id)
+	}
+	for _, id := range rep.Skipped {
+		fmt.Fprintf(out, "skipped %s, it exists\n", id)
+	}
+	return errors.Wrap(err, errRestore)
+}
+
+func newClusterClient() (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+	s, err := newScheme()
+	if err != nil {
+		return nil, err
+	}
+	kube, err := client.New(cfg, client.Options{Scheme: s})
+	return kube, errors.Wrap(err, errNewClient)
+}
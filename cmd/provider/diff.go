@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/terraform"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/config"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/diff"
+)
+
+const fmtDiff = "cannot diff %s %s"
+
+// runDiff prints the difference between every managed resource of the
+// supplied manifests and Vault, and returns true if there is any. The
+// manifests also need to contain the ProviderConfigs and Secrets the
+// resources reference.
+func runDiff(ctx context.Context, w io.Writer, files []string, terraformVersion, providerSource, providerVersion string) (bool, error) {
+	objs, kube, err := readManifests(files)
+	if err != nil {
+		return false, err
+	}
+	d := diff.NewDiffer(kube, config.GetProvider(),
+		clients.TerraformSetupBuilder(terraformVersion, providerSource, providerVersion),
+		terraform.NewWorkspaceStore(logging.NewNopLogger()))
+	changed := false
+	for _, o := range objs {
+		mg, ok := o.(xpresource.Managed)
+		if !ok {
+			continue
+		}
+		defaultProviderConfigReference(mg)
+		r, err := d.Diff(ctx, mg)
+		if err != nil {
+			return false, errors.Wrapf(err, fmtDiff, r.Kind, r.Name)
+		}
+		printDiff(w, r)
+		changed = changed || r.HasChanges()
+	}
+	return changed, nil
+}
+
+func printDiff(w io.Writer, r diff.Resource) {
+	switch {
+	case r.Unsupported:
+		fmt.Fprintf(w, "? %s %s: cannot be compared offline\n", r.Kind, r.Name)
+		return
+	case !r.Exists:
+		fmt.Fprintf(w, "+ %s %s: does not exist\n", r.Kind, r.Name)
+	case len(r.Fields) > 0:
+		fmt.Fprintf(w, "~ %s %s\n", r.Kind, r.Name)
+	case r.Drifted:
+		fmt.Fprintf(w, "~ %s %s: differs\n", r.Kind, r.Name)
+	default:
+		fmt.Fprintf(w, "  %s %s: up to date\n", r.Kind, r.Name)
+	}
+	for _, f := range r.Fields {
+		fmt.Fprintf(w, "    %s: %s -> %s\n", f.Path, f.Current, f.Desired)
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/feature"
@@ -29,28 +31,126 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/terrajet/pkg/terraform"
+	"github.com/pkg/errors"
 	"gopkg.in/alecthomas/kingpin.v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/crossplane-contrib/provider-jet-vault/apis"
 	"github.com/crossplane-contrib/provider-jet-vault/config"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/backup"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/binding"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/bundle"
 	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
 	"github.com/crossplane-contrib/provider-jet-vault/internal/controller"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	debugserver "github.com/crossplane-contrib/provider-jet-vault/internal/debug"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/lock"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/sandbox"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/workspace"
 )
 
+const errTerraformFlags = "required flag(s) %s not provided"
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == sandbox.Command {
+		kingpin.FatalIfError(runSandboxExec(os.Args[2:]), "Cannot run sandboxed command")
+		return
+	}
 	var (
 		app              = kingpin.New(filepath.Base(os.Args[0]), "Terraform based Crossplane provider for Vault").DefaultEnvars()
 		debug            = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
 		syncPeriod       = app.Flag("sync", "Controller manager sync period such as 300ms, 1.5h, or 2h45m").Short('s').Default("1h").Duration()
 		leaderElection   = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
-		terraformVersion = app.Flag("terraform-version", "Terraform version.").Required().Envar("TERRAFORM_VERSION").String()
-		providerSource   = app.Flag("terraform-provider-source", "Terraform provider source.").Required().Envar("TERRAFORM_PROVIDER_SOURCE").String()
-		providerVersion  = app.Flag("terraform-provider-version", "Terraform provider version.").Required().Envar("TERRAFORM_PROVIDER_VERSION").String()
+		terraformVersion = app.Flag("terraform-version", "Terraform version. Required by start, render and diff.").Envar("TERRAFORM_VERSION").String()
+		providerSource   = app.Flag("terraform-provider-source", "Terraform provider source. Required by start, render and diff.").Envar("TERRAFORM_PROVIDER_SOURCE").String()
+		providerVersion  = app.Flag("terraform-provider-version", "Terraform provider version. Required by start, render and diff.").Envar("TERRAFORM_PROVIDER_VERSION").String()
 		maxReconcileRate = app.Flag("max-reconcile-rate", "The global maximum rate per second at which resources may checked for drift from the desired state.").Default("10").Int()
+		debugAddr        = app.Flag("debug-addr", "Address to serve the authenticated debug endpoints on, such as :8081, which listens on localhost. They are disabled if it is empty.").String()
+		debugToken       = app.Flag("debug-token", "Bearer token requests to the debug endpoints have to be authenticated with.").Envar("DEBUG_TOKEN").String()
+		debugCertDir     = app.Flag("debug-tls-cert-dir", "Directory of the tls.crt and tls.key files the debug endpoints are served with. They are served over plain HTTP, which is only allowed on loopback addresses, if it is empty.").Envar("DEBUG_TLS_CERT_DIR").String()
+		batchInterval    = app.Flag("batch-interval", "Interval to observe the Terraform resources that share a ProviderConfig and kind at in one workspace, such as 1m. Every resource is observed in its own workspace if it is 0.").Default("0s").Duration()
+		pollMin          = app.Flag("poll-interval-min", "Minimum interval to poll managed resources that drift often at.").Default("15s").Duration()
+		pollMax          = app.Flag("poll-interval-max", "Maximum interval to poll managed resources that do not drift at.").Default("10m").Duration()
+		maxProcesses     = app.Flag("max-terraform-processes", "Maximum number of Terraform commands that may run at the same time. There is no limit if it is 0.").Default("10").Int()
+		memoryBudget     = app.Flag("terraform-memory-budget", "Estimated memory the Terraform commands that run at the same time may use together, such as 1GB. There is no budget if it is 0.").Default("0").Bytes()
+		processMemory    = app.Flag("terraform-process-memory", "Estimated memory of a Terraform command and its provider plugin.").Default("96MB").Bytes()
+		terraformTimeout = app.Flag("terraform-timeout", "Time after which a Terraform command and the provider plugins it started are killed. Commands do not time out if it is 0.").Default("10m").Duration()
+		expiryWarning    = app.Flag("expiry-warning", "Time before managed resources expire, as set in their vault.jet.crossplane.io/expires-at or ttl annotations, that a warning event is emitted.").Default("1h").Duration()
+		lockTimeout      = app.Flag("vault-path-lock-timeout", "Time operations on managed resources that write to the same Vault path with the same ProviderConfig wait for each other at most.").Default("1m").Duration()
+		tfSandbox        = app.Flag("terraform-sandbox", "Run Terraform with an allowlisted environment, a HOME and plugin cache in its workspace, a read-only filesystem apart from its workspace where the kernel supports Landlock, and resource limits.").Default("true").Bool()
+		tfEnv            = app.Flag("terraform-env", "Additional environment variable Terraform is run with in the sandbox, such as HTTPS_PROXY. A trailing * matches every variable with the prefix.").Strings()
+		tfCPUSeconds     = app.Flag("terraform-cpu-seconds", "CPU time a sandboxed Terraform command may use. It is not limited if it is 0.").Default("0").Uint64()
+		tfAddressSpace   = app.Flag("terraform-address-space", "Virtual memory a sandboxed Terraform process may map, such as 4GB. It is not limited if it is 0.").Default("0").Bytes()
+		tfOpenFiles      = app.Flag("terraform-open-files", "Number of files a sandboxed Terraform process may have open. It is not limited if it is 0.").Default("0").Uint64()
+		secretSelector   = app.Flag("secret-label-selector", "Label selector of the Secrets ProviderConfigs and managed resources may reference, such as vault.crossplane.io/credentials=true. Only these Secrets are cached.").String()
+		secretNamespace  = app.Flag("secret-namespace", "Namespace of the Secrets ProviderConfigs and managed resources may reference. Secrets of every namespace are cached if it is empty.").String()
+		webhookCertDir   = app.Flag("webhook-tls-cert-dir", "Directory of the tls.crt and tls.key files the webhook that binds managed resources created without a ProviderConfig to the one of their ProviderConfigBinding is served with. The webhook is disabled if it is empty.").Envar("WEBHOOK_TLS_CERT_DIR").String()
+		webhookPort      = app.Flag("webhook-port", "Port the webhook is served on.").Default("9443").Int()
+
+		renderCmd       = app.Command("render", "Print the Terraform configuration and environment of managed resources with sensitive values masked.")
+		renderManifests = renderCmd.Arg("manifests", "Manifests of the managed resources and of the ProviderConfigs and Secrets they reference.").Required().ExistingFiles()
+
+		diffCmd       = app.Command("diff", "Print the changes managed resources would make to Vault with sensitive values masked. Exits with 1 if there are changes.")
+		diffManifests = diffCmd.Arg("manifests", "Manifests of the managed resources and of the ProviderConfigs and Secrets they reference.").Required().ExistingFiles()
+
+		bundleCmd          = app.Command("support-bundle", "Collect a tarball of workspace files, ProviderConfig statuses, managed resource conditions and logs with secrets redacted. Run it in the provider container.")
+		bundleOutput       = bundleCmd.Flag("output", "File the tarball is written to.").Short('o').Default("support-bundle.tar.gz").String()
+		bundleWorkspaceDir = bundleCmd.Flag("workspace-dir", "Directory of the Terraform workspaces.").Default(os.TempDir()).String()
+		bundleNamespace    = bundleCmd.Flag("namespace", "Namespace of the provider pod. Defaults to the namespace of the current pod.").String()
+		bundlePod          = bundleCmd.Flag("pod", "Name of the provider pod whose logs are collected. Defaults to the current pod.").String()
+		bundleLogLines     = bundleCmd.Flag("log-lines", "Number of most recent log lines to collect.").Default("5000").Int64()
+
+		backupCmd             = app.Command("backup", "Export the managed resources, the ProviderConfigs and Secrets they reference and the Vault data of generic Secrets into an archive encrypted with age. The Vault objects of other kinds are recreated from their specs on restore.")
+		backupOutput          = backupCmd.Flag("output", "File the archive is written to.").Short('o').Default("backup.tar.gz.age").String()
+		backupRecipients      = backupCmd.Flag("recipient", "age recipient the archive is encrypted to, such as age1...").Short('r').Strings()
+		backupRecipientsFiles = backupCmd.Flag("recipients-file", "File of age recipients the archive is encrypted to.").Strings()
+		backupAge             = backupCmd.Flag("age", "Path of the age binary.").Default("age").String()
+
+		restoreCmd        = app.Command("restore", "Restore an archive written by backup into the current cluster and the Vaults of its ProviderConfigs. Existing objects and Vault data are not overwritten.")
+		restoreInput      = restoreCmd.Arg("archive", "Archive written by backup.").Required().ExistingFile()
+		restoreIdentities = restoreCmd.Flag("identity", "age identity file the archive is decrypted with.").Short('i').Required().ExistingFiles()
+		restoreAge        = restoreCmd.Flag("age", "Path of the age binary.").Default("age").String()
 	)
-	kingpin.MustParse(app.Parse(os.Args[1:]))
+	// The provider is started unless another command is given.
+	startCmd := app.Command("start", "Start the provider.").Default()
+	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
+	switch cmd {
+	case startCmd.FullCommand(), renderCmd.FullCommand(), diffCmd.FullCommand():
+		// Only the commands that run Terraform need to know its version and
+		// the one of the provider.
+		app.FatalIfError(requireTerraformFlags(*terraformVersion, *providerSource, *providerVersion), "")
+	}
+	switch cmd {
+	case renderCmd.FullCommand():
+		kingpin.FatalIfError(runRender(context.Background(), os.Stdout, *renderManifests, *terraformVersion, *providerSource, *providerVersion), "Cannot render Terraform configuration")
+		return
+	case diffCmd.FullCommand():
+		changed, err := runDiff(context.Background(), os.Stdout, *diffManifests, *terraformVersion, *providerSource, *providerVersion)
+		if err != nil {
+			app.Errorf("Cannot diff managed resources: %v", err)
+			os.Exit(2)
+		}
+		if changed {
+			os.Exit(1)
+		}
+		return
+	case bundleCmd.FullCommand():
+		kingpin.FatalIfError(runSupportBundle(context.Background(), *bundleOutput, bundle.Options{
+			WorkspaceDir: *bundleWorkspaceDir,
+			Namespace:    *bundleNamespace,
+			Pod:          *bundlePod,
+			LogLines:     *bundleLogLines,
+		}), "Cannot collect support bundle")
+		return
+	case backupCmd.FullCommand():
+		kingpin.FatalIfError(runBackup(context.Background(), *backupOutput, backup.NewAge(*backupAge), *backupRecipients, *backupRecipientsFiles), "Cannot back up")
+		return
+	case restoreCmd.FullCommand():
+		kingpin.FatalIfError(runRestore(context.Background(), os.Stdout, *restoreInput, backup.NewAge(*restoreAge), *restoreIdentities), "Cannot restore")
+		return
+	}
 
 	zl := zap.New(zap.UseDevMode(*debug))
 	log := logging.NewLogrLogger(zl.WithName("provider-jet-vault"))
@@ -63,6 +163,9 @@ func main() {
 
 	log.Debug("Starting", "sync-period", syncPeriod.String())
 
+	newCache, newClient, err := clients.CacheOptions(*secretSelector, *secretNamespace)
+	kingpin.FatalIfError(err, "Cannot configure informer cache")
+
 	cfg, err := ctrl.GetConfig()
 	kingpin.FatalIfError(err, "Cannot get API server rest config")
 
@@ -73,21 +176,80 @@ func main() {
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaseDuration:              func() *time.Duration { d := 60 * time.Second; return &d }(),
 		RenewDeadline:              func() *time.Duration { d := 50 * time.Second; return &d }(),
+		NewCache:                   newCache,
+		NewClient:                  newClient,
+		Port:                       *webhookPort,
+		CertDir:                    *webhookCertDir,
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
-	o := tjcontroller.Options{
-		Options: xpcontroller.Options{
-			Logger:                  log,
-			GlobalRateLimiter:       ratelimiter.NewGlobal(*maxReconcileRate),
-			PollInterval:            1 * time.Minute,
-			MaxConcurrentReconciles: 1,
-			Features:                &feature.Flags{},
+	ws := terraform.NewWorkspaceStore(log)
+	setupFn := clients.TerraformSetupBuilder(*terraformVersion, *providerSource, *providerVersion)
+	if *tfSandbox {
+		kingpin.FatalIfError(workspace.Wrap(ws).EnableSandbox(sandbox.Options{
+			Env: append(append([]string{}, sandbox.DefaultEnv...), *tfEnv...),
+			Limits: sandbox.Limits{
+				CPUSeconds:   *tfCPUSeconds,
+				AddressSpace: uint64(*tfAddressSpace),
+				OpenFiles:    *tfOpenFiles,
+			},
+			ReadOnly: true,
+		}), "Cannot set up Terraform sandbox")
+	}
+	workspace.Wrap(ws).EnablePool(workspace.PoolOptions{
+		MaxProcesses:  *maxProcesses,
+		MemoryBudget:  int64(*memoryBudget),
+		ProcessMemory: int64(*processMemory),
+		Timeout:       *terraformTimeout,
+	})
+	if *batchInterval > 0 {
+		b := workspace.Wrap(ws).EnableBatching(clients.WithCachedSecrets(mgr.GetClient()), setupFn, *batchInterval, log)
+		kingpin.FatalIfError(mgr.Add(b), "Cannot add batched observations to manager")
+	}
+	if *debugAddr != "" {
+		srv, err := debugserver.NewServer(*debugAddr, *debugToken, *debugCertDir, workspace.Wrap(ws), log)
+		kingpin.FatalIfError(err, "Cannot create debug server")
+		kingpin.FatalIfError(mgr.Add(srv), "Cannot add debug server to manager")
+	}
+	o := options.Options{
+		Options: tjcontroller.Options{
+			Options: xpcontroller.Options{
+				Logger:                  log,
+				GlobalRateLimiter:       ratelimiter.NewGlobal(*maxReconcileRate),
+				PollInterval:            1 * time.Minute,
+				MaxConcurrentReconciles: 1,
+				Features:                &feature.Flags{},
+			},
+			Provider:       config.GetProvider(),
+			WorkspaceStore: ws,
+			SetupFn:        setupFn,
 		},
-		Provider:       config.GetProvider(),
-		WorkspaceStore: terraform.NewWorkspaceStore(log),
-		SetupFn:        clients.TerraformSetupBuilder(*terraformVersion, *providerSource, *providerVersion),
+		PollLimits:    poll.Limits{Min: *pollMin, Max: *pollMax},
+		PathLocks:     lock.NewManager(*lockTimeout),
+		ExpiryWarning: *expiryWarning,
 	}
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Vault APIs to scheme")
 	kingpin.FatalIfError(controller.Setup(mgr, o), "Cannot setup Vault controllers")
+	if *webhookCertDir != "" {
+		binding.Setup(mgr, log)
+	}
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
+
+// requireTerraformFlags returns an error naming the Terraform flags of the
+// supplied values that are not set.
+func requireTerraformFlags(terraformVersion, providerSource, providerVersion string) error {
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{name: "--terraform-version", value: terraformVersion},
+		{name: "--terraform-provider-source", value: providerSource},
+		{name: "--terraform-provider-version", value: providerVersion},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf(errTerraformFlags, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis"
+	"github.com/crossplane-contrib/provider-jet-vault/config"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/render"
+)
+
+const (
+	errOpenManifest = "cannot open manifest"
+	errReadManifest = "cannot read manifests"
+	errNoManaged    = "no managed resource reconciled with Terraform in the manifests"
+	fmtRender       = "cannot render %s %s"
+)
+
+// newScheme returns a scheme with the Kubernetes and provider APIs.
+func newScheme() (*runtime.Scheme, error) {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	return s, apis.AddToScheme(s)
+}
+
+// readManifests returns the objects of the supplied manifest files and a
+// client that serves them in place of a Kubernetes cluster.
+func readManifests(files []string) ([]client.Object, client.Client, error) {
+	s, err := newScheme()
+	if err != nil {
+		return nil, nil, err
+	}
+	streams := make([]io.Reader, 0, len(files))
+	for _, f := range files {
+		r, err := os.Open(filepath.Clean(f))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errOpenManifest)
+		}
+		defer r.Close() // nolint:errcheck,gosec
+		streams = append(streams, r)
+	}
+	objs, err := render.ReadObjects(s, streams...)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errReadManifest)
+	}
+	return objs, fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(), nil
+}
+
+// defaultProviderConfigReference sets the ProviderConfig reference of the
+// supplied managed resource to the default one if it is not set. The API
+// server defaults the reference, which local manifests usually omit.
+func defaultProviderConfigReference(mg xpresource.Managed) {
+	if mg.GetProviderConfigReference() == nil {
+		mg.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+	}
+}
+
+// runRender prints the Terraform configuration and environment of every
+// managed resource of the supplied manifests, which also need to contain the
+// ProviderConfigs and Secrets they reference.
+func runRender(ctx context.Context, w io.Writer, files []string, terraformVersion, providerSource, providerVersion string) error {
+	objs, kube, err := readManifests(files)
+	if err != nil {
+		return err
+	}
+	pc := config.GetProvider()
+	setupFn := clients.TerraformSetupBuilder(terraformVersion, providerSource, providerVersion)
+	rendered := 0
+	for _, o := range objs {
+		if _, ok := o.(resource.Terraformed); !ok {
+			continue
+		}
+		mg, ok := o.(xpresource.Managed)
+		if !ok {
+			continue
+		}
+		defaultProviderConfigReference(mg)
+		kind := o.GetObjectKind().GroupVersionKind().Kind
+		ws, err := render.Render(ctx, kube, pc, setupFn, o)
+		if err != nil {
+			return errors.Wrapf(err, fmtRender, kind, o.GetName())
+		}
+		fmt.Fprintf(w, "# %s %s\n\n## main.tf.json\n\n%s\n\n## Environment\n\n%s\n\n", kind, o.GetName(), ws.MainTF, strings.Join(ws.Env, "\n"))
+		rendered++
+	}
+	if rendered == 0 {
+		return errors.New(errNoManaged)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/sandbox"
+)
+
+// runSandboxExec runs the command given in the supplied arguments in the
+// sandbox. The provider runs Terraform through it, with an environment that
+// lacks the variables the provider itself needs, so it is parsed on its own.
+func runSandboxExec(args []string) error {
+	var (
+		app          = kingpin.New(sandbox.Command, "Run a command in the Terraform sandbox.")
+		writable     = app.Flag("writable", "Path that stays writable.").Strings()
+		cpuSeconds   = app.Flag("cpu-seconds", "CPU time the command may use.").Uint64()
+		addressSpace = app.Flag("address-space", "Virtual memory in bytes a process may map.").Uint64()
+		openFiles    = app.Flag("open-files", "Number of files a process may have open.").Uint64()
+		readOnly     = app.Flag("read-only", "Make the filesystem apart from the writable paths read-only.").Bool()
+		command      = app.Arg("command", "Command and its arguments.").Required().Strings()
+	)
+	if _, err := app.Parse(args); err != nil {
+		return err
+	}
+	return sandbox.Exec(sandbox.ExecOptions{
+		Writable: *writable,
+		Limits: sandbox.Limits{
+			CPUSeconds:   *cpuSeconds,
+			AddressSpace: *addressSpace,
+			OpenFiles:    *openFiles,
+		},
+		ReadOnly: *readOnly,
+	}, *command)
+}
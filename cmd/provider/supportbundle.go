@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/bundle"
+)
+
+// fileNamespace holds the namespace of the pod the provider runs in.
+const fileNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+const (
+	errGetConfig    = "cannot get API server rest config"
+	errNewClient    = "cannot create Kubernetes client"
+	errCreateBundle = "cannot create support bundle file"
+	errCollect      = "cannot collect support bundle"
+)
+
+// runSupportBundle collects a support bundle into the supplied file. It is
+// meant to be run in the provider's container, whose temporary directory
+// holds the Terraform workspaces, e.g. with kubectl exec. The pod and
+// namespace of the provider default to the ones the command runs in.
+func runSupportBundle(ctx context.Context, output string, o bundle.Options) error {
+	if o.Pod == "" {
+		o.Pod, _ = os.Hostname()
+	}
+	if o.Namespace == "" {
+		ns, _ := ioutil.ReadFile(fileNamespace)
+		o.Namespace = strings.TrimSpace(string(ns))
+	}
+	if o.Namespace == "" {
+		// Logs can only be collected in cluster.
+		o.Pod = ""
+	}
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, errGetConfig)
+	}
+	s, err := newScheme()
+	if err != nil {
+		return err
+	}
+	kube, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return errors.Wrap(err, errNewClient)
+	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, errNewClient)
+	}
+	f, err := os.OpenFile(filepath.Clean(output), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, errCreateBundle)
+	}
+	if err := bundle.NewCollector(kube, cs.CoreV1(), o).Collect(ctx, f); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, errCollect)
+	}
+	return errors.Wrap(f.Close(), errCreateBundle)
+}
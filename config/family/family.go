@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package family contains the resource families the provider can be built
+// for. A family is a set of API groups that is built into a provider image
+// and package of its own, so that clusters only install the CRDs of the
+// families they use. The ProviderConfig API is part of the config family,
+// whose package the packages of the other families depend on. Their
+// controllers still read ProviderConfigs, but neither install their CRDs nor
+// reconcile them.
+//
+// Families are selected at build time with a family_<name> build tag per
+// family, e.g. -tags family_kv,family_auth. All of them are included if no
+// family is selected.
+package family
+
+import "sort"
+
+// A Family is a set of API groups that is built as a provider of its own.
+type Family struct {
+	// Name of the family, which is also the suffix of its build tag.
+	Name string
+
+	// ShortGroups are the API groups of the family, e.g. generic.
+	ShortGroups []string
+}
+
+// Config is the name of the family of the ProviderConfig API.
+const Config = "config"
+
+// All families the provider's resources are split into. Every API group has
+// to be in exactly one of them.
+var All = []Family{
+	{Name: Config, ShortGroups: []string{""}},
+	{Name: "auth", ShortGroups: []string{"auth"}},
+	{Name: "identity", ShortGroups: []string{"identity", "oidc"}},
+	{Name: "kv", ShortGroups: []string{"generic", "kv"}},
+	{Name: "sync", ShortGroups: []string{"sync"}},
+	{Name: "sys", ShortGroups: []string{"sys"}},
+}
+
+// selected are the names of the families selected by build tags.
+var selected []string
+
+// Selected returns the families the provider is built for.
+func Selected() []Family {
+	if len(selected) == 0 {
+		return All
+	}
+	names := map[string]bool{}
+	for _, n := range selected {
+		names[n] = true
+	}
+	result := make([]Family, 0, len(selected))
+	for _, f := range All {
+		if names[f.Name] {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Names returns the sorted names of the selected families.
+func Names() []string {
+	fs := Selected()
+	names := make([]string, len(fs))
+	for i, f := range fs {
+		names[i] = f.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IncludesGroup returns true if the supplied short API group is part of the
+// selected families. The ProviderConfig's group has no short group.
+func IncludesGroup(shortGroup string) bool {
+	for _, f := range Selected() {
+		for _, g := range f.ShortGroups {
+			if g == shortGroup {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi contains the configuration of the managed resources that
+// are generated from the Vault OpenAPI document rather than the Terraform
+// provider schema.
+package openapi
+
+import (
+	"strings"
+
+	tjconfig "github.com/crossplane/terrajet/pkg/config"
+
+	"github.com/crossplane-contrib/provider-jet-vault/config/family"
+)
+
+// DocumentPath is the path of the Vault OpenAPI document relative to the
+// repository root. It is the response of sys/internal/specs/openapi, see the
+// openapi.fetch make target.
+const DocumentPath = "config/openapi/openapi.json"
+
+// Version is the API version of the generated resources.
+const Version = "v1alpha1"
+
+// A Resource is an API path of the Vault OpenAPI document that is generated
+// as a managed resource. The path needs to support read and write operations.
+type Resource struct {
+	// Path of the resource in the OpenAPI document, e.g.
+	// /identity/oidc/client/{name}. Its parameters become required
+	// parameters of the resource.
+	Path string
+
+	// ShortGroup of the resource, e.g. oidc.
+	ShortGroup string
+
+	// Kind of the resource, e.g. Client.
+	Kind string
+}
+
+// APIPackage returns the path of the API package of the resource relative to
+// the module.
+func (r Resource) APIPackage() string {
+	return "apis/" + r.ShortGroup + "/" + Version
+}
+
+// ControllerPackage returns the path of the controller package of the
+// resource relative to the module.
+func (r Resource) ControllerPackage() string {
+	return "internal/controller/" + r.ShortGroup + "/" + strings.ToLower(r.Kind)
+}
+
+// Resources are the API paths generated as managed resources. They should
+// not overlap with the resources of the Terraform provider.
+var Resources = []Resource{
+	{Path: "/identity/oidc/assignment/{name}", ShortGroup: "oidc", Kind: "Assignment"},
+	{Path: "/identity/oidc/client/{name}", ShortGroup: "oidc", Kind: "Client"},
+	{Path: "/identity/oidc/provider/{name}", ShortGroup: "oidc", Kind: "Provider"},
+	{Path: "/identity/oidc/scope/{name}", ShortGroup: "oidc", Kind: "Scope"},
+}
+
+// Selected returns the resources that are part of the families the provider
+// is built for.
+func Selected() []Resource {
+	result := make([]Resource, 0, len(Resources))
+	for _, r := range Resources {
+		if family.IncludesGroup(r.ShortGroup) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// BasePackages returns the API and controller packages of the selected
+// resources.
+func BasePackages() tjconfig.BasePackages {
+	pkgs := tjconfig.BasePackages{}
+	seen := map[string]bool{}
+	for _, r := range Selected() {
+		if !seen[r.APIPackage()] {
+			pkgs.APIVersion = append(pkgs.APIVersion, r.APIPackage())
+			seen[r.APIPackage()] = true
+		}
+		pkgs.Controller = append(pkgs.Controller, r.ControllerPackage())
+	}
+	return pkgs
+}
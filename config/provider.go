@@ -19,11 +19,15 @@ package config
 import (
 	// Note(turkenh): we are importing this to embed provider schema document
 	_ "embed"
+	"sort"
+	"strings"
 
 	tjconfig "github.com/crossplane/terrajet/pkg/config"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
+	"github.com/crossplane-contrib/provider-jet-vault/config/family"
 	"github.com/crossplane-contrib/provider-jet-vault/config/generic"
+	"github.com/crossplane-contrib/provider-jet-vault/config/openapi"
 )
 
 const (
@@ -34,6 +38,77 @@ const (
 //go:embed schema.json
 var providerSchema string
 
+// basePackages are the API and controller packages that are not generated
+// from the Terraform schema, i.e. the ProviderConfig and the resources that
+// are reconciled through the Vault API directly. They are keyed by their
+// short API group, the ProviderConfig's being empty.
+var basePackages = map[string]tjconfig.BasePackages{
+	"": {
+		APIVersion: []string{"apis/v1alpha1"},
+		Controller: []string{"internal/controller/providerconfig"},
+	},
+	"auth": {
+		APIVersion: []string{"apis/auth/v1alpha1"},
+		Controller: []string{"internal/controller/auth/userpassuser"},
+	},
+	"identity": {
+		APIVersion: []string{"apis/identity/v1alpha1"},
+		Controller: []string{"internal/controller/identity/oidctoken"},
+	},
+	"kv": {
+		APIVersion: []string{"apis/kv/v1alpha1"},
+		Controller: []string{"internal/controller/kv/secretcopy"},
+	},
+	"sync": {
+		APIVersion: []string{"apis/sync/v1alpha1"},
+		Controller: []string{
+			"internal/controller/sync/destination",
+			"internal/controller/sync/association",
+		},
+	},
+	"sys": {
+		APIVersion: []string{"apis/sys/v1alpha1"},
+		Controller: []string{
+			"internal/controller/sys/replicationprimary",
+			"internal/controller/sys/replicationsecondarytoken",
+			"internal/controller/sys/replicationsecondary",
+			"internal/controller/sys/replicationpathsfilter",
+			"internal/controller/sys/corsconfig",
+			"internal/controller/sys/uiheader",
+			"internal/controller/sys/auditrequestheader",
+			"internal/controller/sys/managedscope",
+		},
+	},
+}
+
+// selectedBasePackages returns the base packages of the selected families and
+// the ones generated for them from the Vault OpenAPI document.
+func selectedBasePackages() tjconfig.BasePackages {
+	groups := make([]string, 0, len(basePackages))
+	for g := range basePackages {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	pkgs := tjconfig.BasePackages{}
+	for _, g := range groups {
+		if !family.IncludesGroup(g) {
+			// The ProviderConfig API is registered by every family, since
+			// their controllers read ProviderConfigs, but only reconciled by
+			// the config family.
+			if g == "" {
+				pkgs.APIVersion = append(pkgs.APIVersion, basePackages[g].APIVersion...)
+			}
+			continue
+		}
+		pkgs.APIVersion = append(pkgs.APIVersion, basePackages[g].APIVersion...)
+		pkgs.Controller = append(pkgs.Controller, basePackages[g].Controller...)
+	}
+	generated := openapi.BasePackages()
+	pkgs.APIVersion = append(pkgs.APIVersion, generated.APIVersion...)
+	pkgs.Controller = append(pkgs.Controller, generated.Controller...)
+	return pkgs
+}
+
 // GetProvider returns provider configuration
 func GetProvider() *tjconfig.Provider {
 	defaultResourceFn := func(name string, terraformResource *schema.Resource, opts ...tjconfig.ResourceOption) *tjconfig.Resource {
@@ -45,6 +120,7 @@ func GetProvider() *tjconfig.Provider {
 
 	pc := tjconfig.NewProviderWithSchema([]byte(providerSchema), resourcePrefix, modulePath,
 		tjconfig.WithDefaultResourceFn(defaultResourceFn),
+		tjconfig.WithBasePackages(selectedBasePackages()),
 		tjconfig.WithIncludeList([]string{
 			"vault_generic_secret$",
 		}))
@@ -56,5 +132,13 @@ func GetProvider() *tjconfig.Provider {
 	}
 
 	pc.ConfigureResources()
+
+	// Resources of the families the provider is not built for are neither
+	// generated nor reconciled.
+	for name, r := range pc.Resources {
+		if !family.IncludesGroup(strings.ToLower(r.ShortGroup)) {
+			delete(pc.Resources, name)
+		}
+	}
 	return pc
 }
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/utils/exec"
+)
+
+const (
+	errNoRecipients = "at least one age recipient is required"
+	errStartAge     = "cannot start age"
+	errAge          = "age failed"
+)
+
+// Age encrypts and decrypts archives with the age command line tool.
+type Age struct {
+	// Binary is the path of the age binary.
+	Binary string
+
+	exec exec.Interface
+}
+
+// NewAge returns an Age that runs the supplied age binary.
+func NewAge(binary string) *Age {
+	return &Age{Binary: binary, exec: exec.New()}
+}
+
+// Encrypt returns a writer that encrypts what is written to it to the
+// supplied recipients and recipients files, and writes the result to the
+// supplied writer. The encryption is only complete once the returned writer
+// is closed.
+func (a *Age) Encrypt(ctx context.Context, w io.Writer, recipients, recipientsFiles []string) (io.WriteCloser, error) {
+	if len(recipients)+len(recipientsFiles) == 0 {
+		return nil, errors.New(errNoRecipients)
+	}
+	args := []string{"--encrypt"}
+	for _, r := range recipients {
+		args = append(args, "--recipient", r)
+	}
+	for _, f := range recipientsFiles {
+		args = append(args, "--recipients-file", f)
+	}
+	pr, pw := io.Pipe()
+	cmd := a.exec.CommandContext(ctx, a.Binary, args...)
+	cmd.SetStdin(pr)
+	cmd.SetStdout(w)
+	stderr := &bytes.Buffer{}
+	cmd.SetStderr(stderr)
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, errStartAge)
+	}
+	return &ageWriter{PipeWriter: pw, cmd: cmd, stderr: stderr}, nil
+}
+
+// Decrypt returns a reader of the decryption of the supplied reader with the
+// supplied identity files. Reading it fails if the decryption fails, and it
+// has to be closed once it is read.
+func (a *Age) Decrypt(ctx context.Context, r io.Reader, identities []string) (io.ReadCloser, error) {
+	args := []string{"--decrypt"}
+	for _, i := range identities {
+		args = append(args, "--identity", i)
+	}
+	pr, pw := io.Pipe()
+	cmd := a.exec.CommandContext(ctx, a.Binary, args...)
+	cmd.SetStdin(r)
+	cmd.SetStdout(pw)
+	stderr := &bytes.Buffer{}
+	cmd.SetStderr(stderr)
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, errStartAge)
+	}
+	go func() {
+		pw.CloseWithError(ageError(cmd.Wait(), stderr))
+	}()
+	return pr, nil
+}
+
+type ageWriter struct {
+	*io.PipeWriter
+	cmd    exec.Cmd
+	stderr *bytes.Buffer
+}
+
+// Close closes the input of age and waits for it to finish writing.
+func (w *ageWriter) Close() error {
+	if err := w.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return ageError(w.cmd.Wait(), w.stderr)
+}
+
+func ageError(err error, stderr *bytes.Buffer) error {
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return errors.Wrapf(err, "%s: %s", errAge, msg)
+	}
+	return errors.Wrap(err, errAge)
+}
@@ -0,0 +1,417 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup exports the state the provider manages, i.e. its managed
+// resources, the Secrets and ProviderConfigs they reference and the Vault
+// data of its secret resources, into archives, and restores them into
+// another cluster and Vault. The provider adopts the restored secret
+// resources and creates the Vault objects of the other kinds anew from their
+// specs.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	rootGroup = "vault.jet.crossplane.io"
+
+	// formatVersion is the version of the layout of the archives.
+	formatVersion = 1
+
+	fileIndex          = "backup.json"
+	dirProviderConfigs = "providerconfigs"
+	dirSecrets         = "secrets"
+	dirManaged         = "managed"
+	dirVault           = "vault"
+
+	annotationLastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+
+	errListProviderConfigs = "cannot list ProviderConfigs"
+	errListManaged         = "cannot list managed resources"
+	errGetSecret           = "cannot get referenced Secret"
+	errConvert             = "cannot convert object"
+	errNewVaultClient      = "cannot create Vault client"
+	errReadVault           = "cannot read Vault data"
+	errWriteArchive        = "cannot write archive"
+	errReadArchive         = "cannot read archive"
+	errFormatVersion       = "unsupported archive format version %d"
+	errCreate              = "cannot create object"
+	errWriteVault          = "cannot write Vault data"
+)
+
+// vaultPaths are the fields of the managed resources of secret kinds that
+// hold the Vault path of their data. The data of these resources is backed
+// up along with them. The Vault objects of the other kinds are not: what
+// their configuration endpoints return cannot be written back as it is, and
+// the provider recreates them from their specs.
+var vaultPaths = map[schema.GroupKind]string{
+	{Group: "generic." + rootGroup, Kind: "Secret"}: "spec.forProvider.path",
+}
+
+// An index describes an archive.
+type index struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// A vaultEntry is the Vault data of a managed resource.
+type vaultEntry struct {
+	// ProviderConfig of the managed resource.
+	ProviderConfig string `json:"providerConfig"`
+	// Path the data is written to.
+	Path string `json:"path"`
+	// Data is the request body that writes the data back.
+	Data map[string]interface{} `json:"data"`
+}
+
+// Backup writes a gzipped tarball of the managed resources of every kind of
+// the provider, the ProviderConfigs and Secrets they reference and the Vault
+// data of the secret resources to the supplied writer. It fails if any of
+// them cannot be read so that archives are always complete.
+func Backup(ctx context.Context, kube client.Client, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	a := &archive{tw: tar.NewWriter(gw), modTime: time.Now()}
+	if err := a.writeJSON(fileIndex, index{Version: formatVersion, CreatedAt: a.modTime}); err != nil {
+		return err
+	}
+	managed, err := listManaged(ctx, kube)
+	if err != nil {
+		return err
+	}
+	configs := map[string]bool{}
+	secrets := map[types.NamespacedName]bool{}
+	for _, mg := range managed {
+		if ref := mg.GetProviderConfigReference(); ref != nil {
+			configs[ref.Name] = true
+		}
+		for _, nn := range poll.SecretRefs(mg) {
+			secrets[nn] = true
+		}
+		if err := backupManaged(ctx, kube, a, mg); err != nil {
+			return err
+		}
+	}
+	if err := backupProviderConfigs(ctx, kube, a, configs); err != nil {
+		return err
+	}
+	for nn := range secrets {
+		s := &corev1.Secret{}
+		if err := kube.Get(ctx, nn, s); err != nil {
+			return errors.Wrapf(err, "%s %s", errGetSecret, nn)
+		}
+		if err := a.writeObject(path.Join(dirSecrets, nn.Namespace, nn.Name+".json"), s, corev1.SchemeGroupVersion.WithKind("Secret")); err != nil {
+			return err
+		}
+	}
+	if err := a.tw.Close(); err != nil {
+		return errors.Wrap(err, errWriteArchive)
+	}
+	return errors.Wrap(gw.Close(), errWriteArchive)
+}
+
+// listManaged returns the managed resources of every kind of the provider
+// that are not being deleted.
+func listManaged(ctx context.Context, kube client.Client) ([]xpresource.Managed, error) {
+	s := kube.Scheme()
+	kinds := make([]schema.GroupVersionKind, 0)
+	for gvk := range s.AllKnownTypes() {
+		if strings.HasSuffix(gvk.Group, rootGroup) && strings.HasSuffix(gvk.Kind, "List") {
+			kinds = append(kinds, gvk)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+
+	var result []xpresource.Managed
+	for _, gvk := range kinds {
+		o, err := s.New(gvk)
+		if err != nil {
+			continue
+		}
+		l, ok := o.(xpresource.ManagedList)
+		if !ok {
+			continue
+		}
+		if err := kube.List(ctx, l); err != nil {
+			return nil, errors.Wrapf(err, "%s %s", errListManaged, gvk.Kind)
+		}
+		for _, mg := range l.GetItems() {
+			if meta.WasDeleted(mg) {
+				continue
+			}
+			// Items of typed lists have no type meta.
+			mg.GetObjectKind().SetGroupVersionKind(gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List")))
+			result = append(result, mg)
+		}
+	}
+	return result, nil
+}
+
+func backupManaged(ctx context.Context, kube client.Client, a *archive, mg xpresource.Managed) error {
+	gvk := mg.GetObjectKind().GroupVersionKind()
+	name := path.Join(gvk.Group, gvk.Kind, mg.GetName()+".json")
+	if err := a.writeObject(path.Join(dirManaged, name), mg, gvk); err != nil {
+		return err
+	}
+	field, ok := vaultPaths[gvk.GroupKind()]
+	if !ok {
+		return nil
+	}
+	pv, err := fieldpath.PaveObject(mg)
+	if err != nil {
+		return errors.Wrap(err, errConvert)
+	}
+	p, err := pv.GetString(field)
+	if err != nil || p == "" {
+		return nil
+	}
+	ref := mg.GetProviderConfigReference()
+	if ref == nil {
+		return nil
+	}
+	vc, err := clients.NewVaultClientForConfig(ctx, kube, ref.Name)
+	if err != nil {
+		return errors.Wrap(err, errNewVaultClient)
+	}
+	s, err := vc.Read(ctx, p)
+	if vault.IsNotFound(err) || (err == nil && s == nil) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s of %s %s", errReadVault, gvk.Kind, mg.GetName())
+	}
+	return a.writeJSON(path.Join(dirVault, name), vaultEntry{ProviderConfig: ref.Name, Path: p, Data: writeBody(s.Data)})
+}
+
+// writeBody returns the request body that writes the supplied data read from
+// Vault back. KV version 2 data is read with its metadata but written on its
+// own.
+func writeBody(data map[string]interface{}) map[string]interface{} {
+	if d, ok := data["data"].(map[string]interface{}); ok && len(data) == 2 && vault.Map(data, "metadata") != nil {
+		return map[string]interface{}{"data": d}
+	}
+	return data
+}
+
+func backupProviderConfigs(ctx context.Context, kube client.Client, a *archive, names map[string]bool) error {
+	l := &v1alpha1.ProviderConfigList{}
+	if err := kube.List(ctx, l); err != nil {
+		return errors.Wrap(err, errListProviderConfigs)
+	}
+	for i := range l.Items {
+		pc := &l.Items[i]
+		if !names[pc.GetName()] {
+			continue
+		}
+		if err := a.writeObject(path.Join(dirProviderConfigs, pc.GetName()+".json"), pc, v1alpha1.ProviderConfigGroupVersionKind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// An archive is a tarball being written.
+type archive struct {
+	tw      *tar.Writer
+	modTime time.Time
+}
+
+// writeObject writes the supplied object without the fields that are set by
+// the API server or only make sense in the cluster it was read from.
+func (a *archive) writeObject(name string, obj client.Object, gvk schema.GroupVersionKind) error {
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return errors.Wrap(err, errConvert)
+	}
+	u := &unstructured.Unstructured{Object: raw}
+	u.SetGroupVersionKind(gvk)
+	u.SetUID("")
+	u.SetResourceVersion("")
+	u.SetGeneration(0)
+	u.SetCreationTimestamp(metav1.Time{})
+	u.SetManagedFields(nil)
+	u.SetFinalizers(nil)
+	// Owners, e.g. composite resources, are not backed up.
+	u.SetOwnerReferences(nil)
+	annotations := u.GetAnnotations()
+	for _, k := range []string{annotationLastApplied, meta.AnnotationKeyExternalCreatePending, meta.AnnotationKeyExternalCreateFailed} {
+		delete(annotations, k)
+	}
+	u.SetAnnotations(annotations)
+	unstructured.RemoveNestedField(u.Object, "status")
+	return a.writeJSON(name, u.Object)
+}
+
+func (a *archive) writeJSON(name string, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errConvert)
+	}
+	h := &tar.Header{Name: name, Mode: 0600, Size: int64(len(raw)), ModTime: a.modTime}
+	if err := a.tw.WriteHeader(h); err != nil {
+		return errors.Wrap(err, errWriteArchive)
+	}
+	_, err = a.tw.Write(raw)
+	return errors.Wrap(err, errWriteArchive)
+}
+
+// A Report lists what a restore did, as kind and name of every object, or
+// Vault path.
+type Report struct {
+	Created []string
+	Skipped []string
+}
+
+// Restore restores the supplied gzipped tarball written by Backup. The
+// ProviderConfigs and Secrets are created first, then the Vault data is
+// written through the restored ProviderConfigs, and the managed resources
+// are created last so that the provider finds the external resources of the
+// secret kinds and adopts them by their external names. Objects and Vault
+// data that already exist are skipped rather than overwritten. The
+// credentials Secrets of the ProviderConfigs are not backed up and have to
+// exist beforehand.
+func Restore(ctx context.Context, kube client.Client, r io.Reader) (Report, error) {
+	rep := Report{}
+	objs, entries, err := readArchive(r)
+	if err != nil {
+		return rep, err
+	}
+	for _, dir := range []string{dirProviderConfigs, dirSecrets} {
+		for _, u := range objs[dir] {
+			if err := create(ctx, kube, u, &rep); err != nil {
+				return rep, err
+			}
+		}
+	}
+	for _, e := range entries {
+		vc, err := clients.NewVaultClientForConfig(ctx, kube, e.ProviderConfig)
+		if err != nil {
+			return rep, errors.Wrap(err, errNewVaultClient)
+		}
+		s, err := vc.Read(ctx, e.Path)
+		if xpresource.Ignore(vault.IsNotFound, err) != nil {
+			return rep, errors.Wrapf(err, "%s %s", errReadVault, e.Path)
+		}
+		if s != nil {
+			rep.Skipped = append(rep.Skipped, "vault "+e.Path)
+			continue
+		}
+		if _, err := vc.Write(ctx, e.Path, e.Data); err != nil {
+			return rep, errors.Wrapf(err, "%s %s", errWriteVault, e.Path)
+		}
+		rep.Created = append(rep.Created, "vault "+e.Path)
+	}
+	for _, u := range objs[dirManaged] {
+		if err := create(ctx, kube, u, &rep); err != nil {
+			return rep, err
+		}
+	}
+	return rep, nil
+}
+
+func create(ctx context.Context, kube client.Client, u *unstructured.Unstructured, rep *Report) error {
+	id := fmt.Sprintf("%s %s", u.GetKind(), u.GetName())
+	if u.GetNamespace() != "" {
+		id = fmt.Sprintf("%s %s/%s", u.GetKind(), u.GetNamespace(), u.GetName())
+	}
+	err := kube.Create(ctx, u)
+	if kerrors.IsAlreadyExists(err) {
+		rep.Skipped = append(rep.Skipped, id)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s %s", errCreate, id)
+	}
+	rep.Created = append(rep.Created, id)
+	return nil
+}
+
+// readArchive returns the objects of the supplied archive by directory and
+// its Vault data, both in the order they were written.
+func readArchive(r io.Reader) (map[string][]*unstructured.Unstructured, []vaultEntry, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errReadArchive)
+	}
+	tr := tar.NewReader(gr)
+	objs := map[string][]*unstructured.Unstructured{}
+	var entries []vaultEntry
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errReadArchive)
+		}
+		raw, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errReadArchive)
+		}
+		dir := strings.SplitN(h.Name, "/", 2)[0]
+		switch dir {
+		case fileIndex:
+			i := index{}
+			if err := json.Unmarshal(raw, &i); err != nil {
+				return nil, nil, errors.Wrap(err, errReadArchive)
+			}
+			if i.Version != formatVersion {
+				return nil, nil, errors.Errorf(errFormatVersion, i.Version)
+			}
+		case dirVault:
+			e := vaultEntry{}
+			if err := json.Unmarshal(raw, &e); err != nil {
+				return nil, nil, errors.Wrap(err, errReadArchive)
+			}
+			entries = append(entries, e)
+		default:
+			u := &unstructured.Unstructured{}
+			if err := u.UnmarshalJSON(raw); err != nil {
+				return nil, nil, errors.Wrap(err, errReadArchive)
+			}
+			objs[dir] = append(objs[dir], u)
+		}
+	}
+	return objs, entries, nil
+}
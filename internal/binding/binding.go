@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package binding serves a mutating admission webhook that binds managed
+// resources created without a ProviderConfig to the one of the
+// ProviderConfigBinding that selects them.
+package binding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	// Path the webhook is served at.
+	Path = "/mutate-providerconfigref"
+
+	// AnnotationBinding is the name of the ProviderConfigBinding that bound a
+	// managed resource to its ProviderConfig.
+	AnnotationBinding = "vault.jet.crossplane.io/provider-config-binding"
+
+	// The API server defaults the providerConfigRef of a managed resource to
+	// the default ProviderConfig before it is admitted, so a resource that
+	// references it cannot be told apart from one that references none.
+	defaultProviderConfig = "default"
+
+	errDecode       = "cannot decode managed resource"
+	errListBindings = "cannot list ProviderConfigBindings"
+	errSelector     = "invalid resource selector of ProviderConfigBinding"
+	errPatch        = "cannot set providerConfigRef"
+)
+
+// Setup registers the webhook with the webhook server of the supplied
+// manager.
+func Setup(mgr ctrl.Manager, log logging.Logger) {
+	mgr.GetWebhookServer().Register(Path, &webhook.Admission{Handler: &Defaulter{
+		kube:   mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+		log:    log,
+	}})
+}
+
+// A Defaulter sets the providerConfigRef of the managed resources that are
+// created without one to the ProviderConfig of the ProviderConfigBinding
+// that selects them.
+type Defaulter struct {
+	kube   client.Reader
+	scheme *runtime.Scheme
+	log    logging.Logger
+}
+
+// Handle binds the managed resource of the supplied request. Resources that
+// reference a ProviderConfig other than the default one, or that no
+// ProviderConfigBinding selects, are admitted as they are.
+func (d *Defaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	o, err := d.scheme.New(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind})
+	if err != nil {
+		return admission.Allowed("")
+	}
+	mg, ok := o.(resource.Managed)
+	if !ok {
+		return admission.Allowed("")
+	}
+	if err := json.Unmarshal(req.Object.Raw, mg); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecode))
+	}
+	if ref := mg.GetProviderConfigReference(); ref != nil && ref.Name != defaultProviderConfig {
+		return admission.Allowed("")
+	}
+
+	l := &v1alpha1.ProviderConfigBindingList{}
+	if err := d.kube.List(ctx, l); err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errListBindings))
+	}
+	b, err := Select(l.Items, mg)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if b == nil {
+		return admission.Allowed("")
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecode))
+	}
+	pv := fieldpath.Pave(obj)
+	if err := pv.SetValue("spec.providerConfigRef", map[string]interface{}{"name": b.Spec.ProviderConfigReference.Name}); err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errPatch))
+	}
+	if err := pv.SetString("metadata.annotations["+AnnotationBinding+"]", b.GetName()); err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errPatch))
+	}
+	patched, err := json.Marshal(pv.UnstructuredContent())
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errPatch))
+	}
+	d.log.Debug("Binding managed resource to ProviderConfig", "kind", req.Kind.Kind, "name", mg.GetName(), "binding", b.GetName(), "providerConfig", b.Spec.ProviderConfigReference.Name)
+	return admission.PatchResponseFromRaw(req.Object.Raw, patched)
+}
+
+// Select returns the ProviderConfigBinding with the highest priority of the
+// supplied ones that selects the supplied managed resource, and nil if none
+// does. Of bindings with the same priority, the one whose name sorts first
+// is returned.
+func Select(bindings []v1alpha1.ProviderConfigBinding, mg resource.Managed) (*v1alpha1.ProviderConfigBinding, error) {
+	namespaces := map[string]bool{}
+	for _, ref := range poll.SecretRefs(mg) {
+		namespaces[ref.Namespace] = true
+	}
+	selected := make([]*v1alpha1.ProviderConfigBinding, 0, len(bindings))
+	for i := range bindings {
+		b := &bindings[i]
+		ok, err := selects(b, labels.Set(mg.GetLabels()), namespaces)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s %s", errSelector, b.GetName())
+		}
+		if ok {
+			selected = append(selected, b)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Spec.Priority != selected[j].Spec.Priority {
+			return selected[i].Spec.Priority > selected[j].Spec.Priority
+		}
+		return selected[i].GetName() < selected[j].GetName()
+	})
+	return selected[0], nil
+}
+
+// selects returns true if the supplied binding selects a managed resource
+// with the supplied labels that references Secrets in the supplied
+// namespaces.
+func selects(b *v1alpha1.ProviderConfigBinding, l labels.Set, namespaces map[string]bool) (bool, error) {
+	if b.Spec.ResourceSelector != nil {
+		s, err := metav1.LabelSelectorAsSelector(b.Spec.ResourceSelector)
+		if err != nil {
+			return false, err
+		}
+		if !s.Matches(l) {
+			return false, nil
+		}
+	}
+	if len(b.Spec.SecretNamespaces) == 0 {
+		return true, nil
+	}
+	for _, ns := range b.Spec.SecretNamespaces {
+		if namespaces[ns] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle collects support bundles, tarballs of everything needed to
+// troubleshoot the provider, with every secret value redacted so that they
+// can be attached to bug reports.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/render"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/workspace"
+)
+
+const (
+	rootGroup = "vault.jet.crossplane.io"
+
+	fileMainTF  = "main.tf.json"
+	fileTFState = "terraform.tfstate"
+	fileLock    = ".terraform.lock.hcl"
+
+	fileProviderConfigs = "providerconfigs.json"
+	fileManaged         = "managed.json"
+	fileErrors          = "errors.txt"
+	dirWorkspaces       = "workspaces"
+	dirLogs             = "logs"
+
+	errListProviderConfigs = "cannot list ProviderConfigs"
+	errListManaged         = "cannot list managed resources"
+	errReadWorkspaces      = "cannot read workspace directory"
+	errGetLogs             = "cannot get provider logs"
+	errMarshal             = "cannot marshal bundle file"
+	errWriteFile           = "cannot write bundle file"
+	errClose               = "cannot close bundle"
+)
+
+// Options configure what is collected into a bundle.
+type Options struct {
+	// WorkspaceDir is the directory the Terraform workspaces of the provider
+	// are in, which is the temporary directory of its container.
+	WorkspaceDir string
+
+	// Namespace and Pod of the provider whose logs are collected. Logs are
+	// not collected if Pod is empty.
+	Namespace string
+	Pod       string
+
+	// LogLines is the number of most recent log lines that are collected.
+	LogLines int64
+}
+
+// A Collector collects support bundles.
+type Collector struct {
+	kube client.Client
+	pods typedcorev1.PodsGetter
+	opts Options
+}
+
+// NewCollector returns a Collector that reads the API server through the
+// supplied clients.
+func NewCollector(kube client.Client, pods typedcorev1.PodsGetter, o Options) *Collector {
+	return &Collector{kube: kube, pods: pods, opts: o}
+}
+
+// Collect writes a gzipped tarball with the statuses of ProviderConfigs, the
+// conditions of managed resources, the files of Terraform workspaces and the
+// provider logs to the supplied writer. Parts that cannot be collected are
+// listed in errors.txt of the bundle rather than failing the collection.
+func (c *Collector) Collect(ctx context.Context, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	b := &bundle{tw: tar.NewWriter(gw), modTime: time.Now()}
+
+	b.record(c.collectProviderConfigs(ctx, b))
+	managed, err := c.collectManaged(ctx, b)
+	b.record(err)
+	b.record(c.collectWorkspaces(b, managed))
+	b.record(c.collectLogs(ctx, b))
+	if len(b.errs) > 0 {
+		if err := b.write(fileErrors, []byte(strings.Join(b.errs, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	if err := b.tw.Close(); err != nil {
+		return errors.Wrap(err, errClose)
+	}
+	return errors.Wrap(gw.Close(), errClose)
+}
+
+func (c *Collector) collectProviderConfigs(ctx context.Context, b *bundle) error {
+	l := &v1alpha1.ProviderConfigList{}
+	if err := c.kube.List(ctx, l); err != nil {
+		return errors.Wrap(err, errListProviderConfigs)
+	}
+	// The credentials of ProviderConfigs are references to Secrets, which
+	// are not collected, so ProviderConfigs are safe to include as they are.
+	for i := range l.Items {
+		l.Items[i].ManagedFields = nil
+	}
+	return b.writeJSON(fileProviderConfigs, l.Items)
+}
+
+// A managedSummary is what is collected of a managed resource. Its spec is
+// left out since parameters of generated resources are not guaranteed to be
+// free of secrets.
+type managedSummary struct {
+	APIVersion     string           `json:"apiVersion"`
+	Kind           string           `json:"kind"`
+	Name           string           `json:"name"`
+	UID            string           `json:"uid"`
+	ExternalName   string           `json:"externalName,omitempty"`
+	ProviderConfig string           `json:"providerConfig,omitempty"`
+	Deleted        bool             `json:"deleted,omitempty"`
+	Conditions     []xpv1.Condition `json:"conditions,omitempty"`
+}
+
+// collectManaged collects the managed resources of every kind of the provider
+// and returns them by UID.
+func (c *Collector) collectManaged(ctx context.Context, b *bundle) (map[string]xpresource.Managed, error) {
+	s := c.kube.Scheme()
+	kinds := make([]schema.GroupVersionKind, 0)
+	for gvk := range s.AllKnownTypes() {
+		if strings.HasSuffix(gvk.Group, rootGroup) && strings.HasSuffix(gvk.Kind, "List") {
+			kinds = append(kinds, gvk)
+		}
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+
+	byUID := map[string]xpresource.Managed{}
+	summaries := []managedSummary{}
+	var errs []string
+	for _, gvk := range kinds {
+		o, err := s.New(gvk)
+		if err != nil {
+			continue
+		}
+		l, ok := o.(xpresource.ManagedList)
+		if !ok {
+			continue
+		}
+		if err := c.kube.List(ctx, l); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", gvk.Kind, err))
+			continue
+		}
+		for _, mg := range l.GetItems() {
+			byUID[string(mg.GetUID())] = mg
+			sum := managedSummary{
+				APIVersion:   gvk.GroupVersion().String(),
+				Kind:         strings.TrimSuffix(gvk.Kind, "List"),
+				Name:         mg.GetName(),
+				UID:          string(mg.GetUID()),
+				ExternalName: meta.GetExternalName(mg),
+				Deleted:      meta.WasDeleted(mg),
+			}
+			if ref := mg.GetProviderConfigReference(); ref != nil {
+				sum.ProviderConfig = ref.Name
+			}
+			for _, t := range []xpv1.ConditionType{xpv1.TypeReady, xpv1.TypeSynced} {
+				// Conditions that were never set have no reason.
+				if cond := mg.GetCondition(t); cond.Reason != "" {
+					sum.Conditions = append(sum.Conditions, cond)
+				}
+			}
+			summaries = append(summaries, sum)
+		}
+	}
+	if err := b.writeJSON(fileManaged, summaries); err != nil {
+		return byUID, err
+	}
+	if len(errs) > 0 {
+		return byUID, errors.Errorf("%s: %s", errListManaged, strings.Join(errs, "; "))
+	}
+	return byUID, nil
+}
+
+// collectWorkspaces collects the files of the Terraform workspaces, which
+// are named after the UIDs of their managed resources, and the output of the
+// last Terraform commands run in them. Sensitive arguments and attributes are
+// masked; the files of workspaces whose managed resource is unknown are left
+// out since they cannot be masked. Batched workspaces are shared by many
+// managed resources and left out as well.
+func (c *Collector) collectWorkspaces(b *bundle, managed map[string]xpresource.Managed) error {
+	entries, err := ioutil.ReadDir(c.opts.WorkspaceDir)
+	if err != nil {
+		return errors.Wrap(err, errReadWorkspaces)
+	}
+	var errs []string
+	for _, e := range entries {
+		dir := filepath.Join(c.opts.WorkspaceDir, e.Name())
+		if !e.IsDir() || strings.HasPrefix(e.Name(), workspace.PrefixBatch) || !exists(filepath.Join(dir, fileMainTF)) {
+			continue
+		}
+		tr, ok := managed[e.Name()].(resource.Terraformed)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("workspace %s: no managed resource with this UID, files left out", e.Name()))
+			continue
+		}
+		for name, mask := range map[string]func([]byte, resource.Terraformed) ([]byte, error){
+			fileMainTF:           render.MaskMainTF,
+			fileTFState:          maskState,
+			fileLock:             nil,
+			workspace.FileOutput: maskOutput,
+		} {
+			raw, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, name)))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err == nil && mask != nil {
+				raw, err = mask(raw, tr)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("workspace %s: %s: %s", e.Name(), name, err))
+				continue
+			}
+			if err := b.write(filepath.Join(dirWorkspaces, e.Name(), name), raw); err != nil {
+				return err
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// maskState masks the sensitive attributes of the supplied Terraform state
+// and removes the private data of the Terraform provider.
+func maskState(raw []byte, tr resource.Terraformed) ([]byte, error) {
+	st := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, err
+	}
+	resources, _ := st["resources"].([]interface{})
+	for _, r := range resources {
+		rm, _ := r.(map[string]interface{})
+		instances, _ := rm["instances"].([]interface{})
+		for _, i := range instances {
+			im, ok := i.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			delete(im, "private")
+			attrs, ok := im["attributes"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := render.MaskAttributes(attrs, tr.GetConnectionDetailsMapping()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return json.MarshalIndent(st, "", "  ")
+}
+
+// maskOutput redacts the Vault tokens in the supplied Terraform output,
+// which the provider already did when it recorded it.
+func maskOutput(raw []byte, _ resource.Terraformed) ([]byte, error) {
+	return render.RedactTokens(raw), nil
+}
+
+func (c *Collector) collectLogs(ctx context.Context, b *bundle) error {
+	if c.opts.Pod == "" {
+		return nil
+	}
+	o := &corev1.PodLogOptions{}
+	if c.opts.LogLines > 0 {
+		o.TailLines = &c.opts.LogLines
+	}
+	raw, err := c.pods.Pods(c.opts.Namespace).GetLogs(c.opts.Pod, o).DoRaw(ctx)
+	if err != nil {
+		return errors.Wrap(err, errGetLogs)
+	}
+	return b.write(filepath.Join(dirLogs, c.opts.Pod+".log"), render.RedactTokens(raw))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// A bundle is a tarball being written.
+type bundle struct {
+	tw      *tar.Writer
+	modTime time.Time
+	errs    []string
+}
+
+// record adds the supplied error, if any, to the errors of the bundle.
+func (b *bundle) record(err error) {
+	if err != nil {
+		b.errs = append(b.errs, err.Error())
+	}
+}
+
+func (b *bundle) writeJSON(name string, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, errMarshal)
+	}
+	return b.write(name, raw)
+}
+
+func (b *bundle) write(name string, content []byte) error {
+	h := &tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), ModTime: b.modTime}
+	if err := b.tw.WriteHeader(h); err != nil {
+		return errors.Wrap(err, errWriteFile)
+	}
+	_, err := b.tw.Write(content)
+	return errors.Wrap(err, errWriteFile)
+}
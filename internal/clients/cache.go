@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+const (
+	errParseSecretSelector = "cannot parse Secret label selector"
+
+	fieldNamespace = "metadata.namespace"
+)
+
+// CacheOptions returns the functions the manager creates its informer cache
+// and its client with. The cache only holds the Secrets that match the
+// supplied label selector and that are in the supplied namespace, either of
+// which may be empty, to bound its memory. The client then reads Secrets from
+// the API server, so that the connection Secrets the provider publishes,
+// which are not selected, are found, and exposes the cache as the reader of
+// the Secrets ProviderConfigs and managed resources reference, see
+// SecretReader. Referenced Secrets outside of the selection are not found.
+func CacheOptions(labelSelector, namespace string) (cache.NewCacheFunc, cluster.NewClientFunc, error) {
+	s := cache.ObjectSelector{}
+	if labelSelector != "" {
+		l, err := labels.Parse(labelSelector)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errParseSecretSelector)
+		}
+		s.Label = l
+	}
+	if namespace != "" {
+		s.Field = fields.OneTermEqualSelector(fieldNamespace, namespace)
+	}
+	if s.Label == nil && s.Field == nil {
+		return cache.New, cluster.DefaultNewClient, nil
+	}
+	newCache := cache.BuilderWithOptions(cache.Options{
+		SelectorsByObject: cache.SelectorsByObject{&corev1.Secret{}: s},
+	})
+	return newCache, newSecretScopedClient, nil
+}
+
+// newSecretScopedClient returns a client that reads Secrets from the API
+// server and every other object from the supplied cache, and whose
+// SecretReader is the cache.
+func newSecretScopedClient(c cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
+	kube, err := cluster.DefaultNewClient(c, config, options, append(uncachedObjects, &corev1.Secret{})...)
+	if err != nil {
+		return nil, err
+	}
+	return &secretScopedClient{Client: kube, secrets: c}, nil
+}
+
+type secretScopedClient struct {
+	client.Client
+	secrets client.Reader
+}
+
+func (c *secretScopedClient) SecretReader() client.Reader {
+	return c.secrets
+}
+
+// SecretReader returns the reader of the Secrets that ProviderConfigs and
+// managed resources reference through the supplied client. It is the
+// selector-bound informer cache if one is configured, and the client itself
+// otherwise. Secrets the provider publishes must be read through the client.
+func SecretReader(kube client.Client) client.Reader {
+	if c, ok := kube.(interface{ SecretReader() client.Reader }); ok {
+		return c.SecretReader()
+	}
+	return kube
+}
+
+// WithCachedSecrets returns a client that reads Secrets through the
+// SecretReader of the supplied client, and everything else through the
+// client itself. Secrets the SecretReader does not find, e.g. the connection
+// Secrets the provider publishes, are read through the client. Connectors
+// that resolve the Secrets managed resources reference themselves, e.g. the
+// ones of Terrajet, are given it so that they read the same Secrets as the
+// rest of the provider without a request to the API server.
+func WithCachedSecrets(kube client.Client) client.Client {
+	if _, ok := kube.(interface{ SecretReader() client.Reader }); !ok {
+		return kube
+	}
+	return &cachedSecretsClient{Client: kube, secrets: SecretReader(kube)}
+}
+
+type cachedSecretsClient struct {
+	client.Client
+	secrets client.Reader
+}
+
+func (c *cachedSecretsClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*corev1.Secret); ok {
+		if err := c.secrets.Get(ctx, key, obj); !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *cachedSecretsClient) SecretReader() client.Reader {
+	return c.secrets
+}
+
+// cachedCredentials are the credentials parsed from the Secret of a
+// ProviderConfig, and the resource versions they were parsed at.
+type cachedCredentials struct {
+	configVersion string
+	secretVersion string
+	credentials   map[string]string
+}
+
+// credentialCache keeps the parsed credentials of ProviderConfigs until
+// either the ProviderConfig or its Secret changes.
+type credentialCache struct {
+	mu      sync.RWMutex
+	entries map[string]cachedCredentials
+}
+
+var credCache = &credentialCache{entries: map[string]cachedCredentials{}}
+
+func (c *credentialCache) get(config, configVersion, secretVersion string) (map[string]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[config]
+	if !ok || e.configVersion != configVersion || e.secretVersion != secretVersion {
+		return nil, false
+	}
+	return e.credentials, true
+}
+
+func (c *credentialCache) set(config, configVersion, secretVersion string, creds map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[config] = cachedCredentials{
+		configVersion: configVersion,
+		secretVersion: secretVersion,
+		credentials:   creds,
+	}
+}
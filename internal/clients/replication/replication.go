@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package replication contains the Vault Enterprise replication helpers
+// shared by the controllers of the replication resources.
+package replication
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+const (
+	errReadStatus = "cannot read replication status"
+
+	fmtStatusPath = "sys/replication/%s/status"
+	fmtPath       = "sys/replication/%s/%s"
+)
+
+// Replication roles of a cluster.
+const (
+	ModePrimary   = "primary"
+	ModeSecondary = "secondary"
+)
+
+// Path returns the path of the supplied operation of the supplied replication
+// mode, e.g. Path("dr", "primary/enable").
+func Path(mode, op string) string {
+	return fmt.Sprintf(fmtPath, mode, op)
+}
+
+// Status returns the status of the supplied replication mode of the cluster.
+func Status(ctx context.Context, c *vault.Client, mode string) (v1alpha1.ReplicationStatus, error) {
+	s, err := c.Read(ctx, fmt.Sprintf(fmtStatusPath, mode))
+	if err != nil {
+		return v1alpha1.ReplicationStatus{}, errors.Wrap(err, errReadStatus)
+	}
+	if s == nil {
+		return v1alpha1.ReplicationStatus{}, nil
+	}
+	d := s.Data
+	return v1alpha1.ReplicationStatus{
+		Mode:               vault.String(d, "mode"),
+		State:              vault.String(d, "state"),
+		ConnectionState:    vault.String(d, "connection_state"),
+		ClusterID:          vault.String(d, "cluster_id"),
+		PrimaryClusterAddr: vault.String(d, "primary_cluster_addr"),
+		SecondaryID:        vault.String(d, "secondary_id"),
+		KnownSecondaries:   vault.StringSlice(d, "known_secondaries"),
+		LastWAL:            vault.Int64(d, "last_wal"),
+		LastPerformanceWAL: vault.Int64(d, "last_performance_wal"),
+		LastDRWAL:          vault.Int64(d, "last_dr_wal"),
+		LastRemoteWAL:      vault.Int64(d, "last_remote_wal"),
+		MerkleRoot:         vault.String(d, "merkle_root"),
+	}, nil
+}
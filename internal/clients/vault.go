@@ -20,14 +20,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/terrajet/pkg/terraform"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane-contrib/provider-jet-vault/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
 )
 
 const (
@@ -67,11 +72,14 @@ const (
 	fmtEnvVar = "%s=%s"
 
 	// error messages
-	errNoProviderConfig     = "no providerConfigRef provided"
-	errGetProviderConfig    = "cannot get referenced ProviderConfig"
-	errTrackUsage           = "cannot track ProviderConfig usage"
-	errExtractCredentials   = "cannot extract credentials"
-	errUnmarshalCredentials = "cannot unmarshal vault credentials as JSON"
+	errNoProviderConfig       = "no providerConfigRef provided"
+	errGetProviderConfig      = "cannot get referenced ProviderConfig"
+	errTrackUsage             = "cannot track ProviderConfig usage"
+	errExtractCredentials     = "cannot extract credentials"
+	errNoCredentialsSecretRef = "no credentials Secret reference provided"
+	errGetCredentialsSecret   = "cannot get credentials Secret"
+	errUnmarshalCredentials   = "cannot unmarshal vault credentials as JSON"
+	errNewVaultClient         = "cannot create Vault API client"
 )
 
 // TerraformSetupBuilder builds Terraform a terraform.SetupFn function which
@@ -86,27 +94,9 @@ func TerraformSetupBuilder(version, providerSource, providerVersion string) terr
 			},
 		}
 
-		configRef := mg.GetProviderConfigReference()
-		if configRef == nil {
-			return ps, errors.New(errNoProviderConfig)
-		}
-		pc := &v1alpha1.ProviderConfig{}
-		if err := client.Get(ctx, types.NamespacedName{Name: configRef.Name}, pc); err != nil {
-			return ps, errors.Wrap(err, errGetProviderConfig)
-		}
-
-		t := resource.NewProviderConfigUsageTracker(client, &v1alpha1.ProviderConfigUsage{})
-		if err := t.Track(ctx, mg); err != nil {
-			return ps, errors.Wrap(err, errTrackUsage)
-		}
-
-		data, err := resource.CommonCredentialExtractor(ctx, pc.Spec.Credentials.Source, client, pc.Spec.Credentials.CommonCredentialSelectors)
+		vaultCreds, err := credentials(ctx, client, mg)
 		if err != nil {
-			return ps, errors.Wrap(err, errExtractCredentials)
-		}
-		vaultCreds := map[string]string{}
-		if err := json.Unmarshal(data, &vaultCreds); err != nil {
-			return ps, errors.Wrap(err, errUnmarshalCredentials)
+			return ps, err
 		}
 
 		// set provider configuration
@@ -131,3 +121,125 @@ func TerraformSetupBuilder(version, providerSource, providerVersion string) terr
 		return ps, nil
 	}
 }
+
+// sensitiveEnv are the environment variables of the Terraform setup that
+// hold credentials.
+var sensitiveEnv = map[string]bool{
+	envToken: true,
+}
+
+// MaskEnv returns a copy of the supplied Terraform setup environment in which
+// the values of the variables that hold credentials are replaced with the
+// supplied mask.
+func MaskEnv(env []string, mask string) []string {
+	result := make([]string, len(env))
+	for i, e := range env {
+		result[i] = e
+		if kv := strings.SplitN(e, "=", 2); len(kv) == 2 && sensitiveEnv[kv[0]] && kv[1] != "" {
+			result[i] = fmt.Sprintf(fmtEnvVar, kv[0], mask)
+		}
+	}
+	return result
+}
+
+// credentials returns the Vault credentials of the ProviderConfig referenced
+// by the supplied managed resource and tracks its usage. The supplied client
+// is expected to read from the informer cache of the manager, and its
+// SecretReader is used to read the credentials Secret. Credentials
+// read from a Secret are parsed once per resource version of the
+// ProviderConfig and the Secret. The returned map must not be modified.
+func credentials(ctx context.Context, client client.Client, mg resource.Managed) (map[string]string, error) {
+	configRef := mg.GetProviderConfigReference()
+	if configRef == nil {
+		return nil, errors.New(errNoProviderConfig)
+	}
+	pc := &v1alpha1.ProviderConfig{}
+	if err := client.Get(ctx, types.NamespacedName{Name: configRef.Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetProviderConfig)
+	}
+
+	t := resource.NewProviderConfigUsageTracker(client, &v1alpha1.ProviderConfigUsage{})
+	if err := t.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackUsage)
+	}
+	return configCredentials(ctx, client, pc)
+}
+
+func configCredentials(ctx context.Context, client client.Client, pc *v1alpha1.ProviderConfig) (map[string]string, error) {
+	if pc.Spec.Credentials.Source == xpv1.CredentialsSourceSecret {
+		return secretCredentials(ctx, client, pc)
+	}
+	data, err := resource.CommonCredentialExtractor(ctx, pc.Spec.Credentials.Source, client, pc.Spec.Credentials.CommonCredentialSelectors)
+	if err != nil {
+		return nil, errors.Wrap(err, errExtractCredentials)
+	}
+	return parseCredentials(data)
+}
+
+func secretCredentials(ctx context.Context, client client.Client, pc *v1alpha1.ProviderConfig) (map[string]string, error) {
+	ref := pc.Spec.Credentials.SecretRef
+	if ref == nil {
+		return nil, errors.New(errNoCredentialsSecretRef)
+	}
+	s := &corev1.Secret{}
+	if err := SecretReader(client).Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, errors.Wrap(errors.Wrap(err, errGetCredentialsSecret), errExtractCredentials)
+	}
+	if creds, ok := credCache.get(pc.GetName(), pc.GetResourceVersion(), s.GetResourceVersion()); ok {
+		return creds, nil
+	}
+	creds, err := parseCredentials(s.Data[ref.Key])
+	if err != nil {
+		return nil, err
+	}
+	credCache.set(pc.GetName(), pc.GetResourceVersion(), s.GetResourceVersion(), creds)
+	return creds, nil
+}
+
+func parseCredentials(data []byte) (map[string]string, error) {
+	vaultCreds := map[string]string{}
+	if err := json.Unmarshal(data, &vaultCreds); err != nil {
+		return nil, errors.Wrap(err, errUnmarshalCredentials)
+	}
+	return vaultCreds, nil
+}
+
+// NewVaultClient returns a Vault API client that uses the credentials of the
+// ProviderConfig referenced by the supplied managed resource. It is used by
+// the controllers of resources that are not backed by Terraform.
+func NewVaultClient(ctx context.Context, client client.Client, mg resource.Managed) (*vault.Client, error) {
+	vaultCreds, err := credentials(ctx, client, mg)
+	if err != nil {
+		return nil, err
+	}
+	return newVaultClient(vaultCreds)
+}
+
+// NewVaultClientForConfig returns a Vault API client that uses the
+// credentials of the ProviderConfig with the supplied name. Unlike
+// NewVaultClient, it does not track the usage of the ProviderConfig, so it is
+// meant for resources that use other ProviderConfigs besides their own.
+func NewVaultClientForConfig(ctx context.Context, client client.Client, name string) (*vault.Client, error) {
+	pc := &v1alpha1.ProviderConfig{}
+	if err := client.Get(ctx, types.NamespacedName{Name: name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetProviderConfig)
+	}
+	vaultCreds, err := configCredentials(ctx, client, pc)
+	if err != nil {
+		return nil, err
+	}
+	return newVaultClient(vaultCreds)
+}
+
+func newVaultClient(vaultCreds map[string]string) (*vault.Client, error) {
+	skipTLSVerify, _ := strconv.ParseBool(vaultCreds[keySkipTLSVerify])
+	c, err := vault.New(vault.Config{
+		Address:       vaultCreds[keyVaultAddr],
+		Token:         vaultCreds[keyToken],
+		Namespace:     vaultCreds[keyNamespace],
+		CACertFile:    vaultCreds[keyCaCertFile],
+		CACertDir:     vaultCreds[keyCaCertDir],
+		SkipTLSVerify: skipTLSVerify,
+	})
+	return c, errors.Wrap(err, errNewVaultClient)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vault contains a minimal Vault HTTP API client used by the
+// controllers of resources that have no Terraform counterpart.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	headerToken     = "X-Vault-Token"
+	headerNamespace = "X-Vault-Namespace"
+
+	methodList = "LIST"
+
+	defaultTimeout = 30 * time.Second
+
+	errParseAddress = "cannot parse Vault address"
+	errReadCACert   = "cannot read CA certificate"
+	errAppendCACert = "cannot append CA certificate to the pool"
+	errMarshalBody  = "cannot marshal request body"
+	errNewRequest   = "cannot build request"
+	errDoRequest    = "cannot send request to Vault"
+	errReadBody     = "cannot read response body"
+	errDecodeBody   = "cannot decode response body"
+)
+
+// Config is the configuration of a Client.
+type Config struct {
+	// Address of the Vault server, e.g. https://vault:8200.
+	Address string
+	// Token used to authenticate requests.
+	Token string
+	// Namespace requests are scoped to. Vault Enterprise only.
+	Namespace string
+	// CACertFile is a PEM encoded CA certificate file used to verify the
+	// server certificate.
+	CACertFile string
+	// CACertDir is a directory of PEM encoded CA certificates used to verify
+	// the server certificate.
+	CACertDir string
+	// SkipTLSVerify disables the verification of the server certificate.
+	SkipTLSVerify bool
+	// Timeout of a single request. Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// A Secret is the response body of most Vault API calls.
+type Secret struct {
+	RequestID     string                 `json:"request_id"`
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Warnings      []string               `json:"warnings"`
+	WrapInfo      *WrapInfo              `json:"wrap_info"`
+}
+
+// WrapInfo contains the response wrapping information of a Secret.
+type WrapInfo struct {
+	Token        string `json:"token"`
+	Accessor     string `json:"accessor"`
+	TTL          int    `json:"ttl"`
+	CreationTime string `json:"creation_time"`
+}
+
+// A Client talks to the Vault HTTP API.
+type Client struct {
+	address   *url.URL
+	token     string
+	namespace string
+	http      *http.Client
+}
+
+// New returns a new Client with the supplied configuration.
+func New(cfg Config) (*Client, error) {
+	addr, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseAddress)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.SkipTLSVerify, // nolint:gosec
+	}
+	if cfg.CACertFile != "" || cfg.CACertDir != "" {
+		pool, err := certPool(cfg.CACertFile, cfg.CACertDir)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &Client{
+		address:   addr,
+		token:     cfg.Token,
+		namespace: cfg.Namespace,
+		http: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+	}, nil
+}
+
+func certPool(file, dir string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	files := []string{}
+	if file != "" {
+		files = append(files, file)
+	}
+	if dir != "" {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, errors.Wrap(err, errReadCACert)
+		}
+		files = append(files, matches...)
+	}
+	for _, f := range files {
+		pem, err := ioutil.ReadFile(filepath.Clean(f))
+		if err != nil {
+			return nil, errors.Wrap(err, errReadCACert)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New(errAppendCACert)
+		}
+	}
+	return pool, nil
+}
+
+// WithNamespace returns a copy of the Client whose requests are scoped to the
+// supplied namespace.
+func (c *Client) WithNamespace(namespace string) *Client {
+	cp := *c
+	cp.namespace = namespace
+	return &cp
+}
+
+// Read returns the Secret at the supplied path.
+func (c *Client) Read(ctx context.Context, path string) (*Secret, error) {
+	return c.Do(ctx, http.MethodGet, path, nil)
+}
+
+// Write writes the supplied data to the supplied path and returns the
+// response, if any.
+func (c *Client) Write(ctx context.Context, path string, data map[string]interface{}) (*Secret, error) {
+	return c.Do(ctx, http.MethodPost, path, data)
+}
+
+// Patch partially updates the object at the supplied path.
+func (c *Client) Patch(ctx context.Context, path string, data map[string]interface{}) (*Secret, error) {
+	return c.Do(ctx, http.MethodPatch, path, data)
+}
+
+// Delete deletes the object at the supplied path.
+func (c *Client) Delete(ctx context.Context, path string) error {
+	_, err := c.Do(ctx, http.MethodDelete, path, nil)
+	return err
+}
+
+// List returns the keys under the supplied path. It returns an empty list if
+// there is nothing under the path.
+func (c *Client) List(ctx context.Context, path string) ([]string, error) {
+	s, err := c.Do(ctx, methodList, path, nil)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return StringSlice(s.Data, "keys"), nil
+}
+
+// Do sends a request with the supplied method and body to the supplied path,
+// which is relative to /v1/. The returned Secret is nil if Vault responded
+// with no content.
+func (c *Client) Do(ctx context.Context, method, path string, body interface{}) (*Secret, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrap(err, errMarshalBody)
+		}
+		r = bytes.NewReader(b)
+	}
+	u := *c.address
+	u.Path = "/v1/" + strings.TrimPrefix(path, "/")
+	if method == methodList {
+		// Not every proxy in front of Vault passes custom methods through.
+		method = http.MethodGet
+		u.RawQuery = "list=true"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewRequest)
+	}
+	if c.token != "" {
+		req.Header.Set(headerToken, c.token)
+	}
+	if c.namespace != "" {
+		req.Header.Set(headerNamespace, c.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errDoRequest)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, errReadBody)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newResponseError(method, path, resp.StatusCode, raw)
+	}
+	if resp.StatusCode == http.StatusNoContent || len(bytes.TrimSpace(raw)) == 0 {
+		return nil, nil
+	}
+	s := &Secret{}
+	return s, errors.Wrap(json.Unmarshal(raw, s), errDecodeBody)
+}
+
+// A ResponseError is returned when Vault responds with an error status code.
+type ResponseError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Errors     []string
+}
+
+func newResponseError(method, path string, code int, body []byte) *ResponseError {
+	e := &ResponseError{Method: method, Path: path, StatusCode: code}
+	r := struct {
+		Errors []string `json:"errors"`
+	}{}
+	if err := json.Unmarshal(body, &r); err == nil {
+		e.Errors = r.Errors
+	}
+	return e
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("%s %s: Vault responded with code %d", e.Method, e.Path, e.StatusCode)
+	if len(e.Errors) > 0 {
+		msg += ": " + strings.Join(e.Errors, "; ")
+	}
+	return msg
+}
+
+// IsNotFound returns true if the supplied error indicates that the requested
+// object does not exist.
+func IsNotFound(err error) bool {
+	re := &ResponseError{}
+	return errors.As(err, &re) && re.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// String returns the string value of the supplied key of a response data map.
+func String(data map[string]interface{}, key string) string {
+	switch v := data[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Int64 returns the integer value of the supplied key of a response data map.
+func Int64(data map[string]interface{}, key string) int64 {
+	switch v := data[key].(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		i, _ := v.Int64()
+		return i
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// Bool returns the boolean value of the supplied key of a response data map.
+func Bool(data map[string]interface{}, key string) bool {
+	b, _ := data[key].(bool)
+	return b
+}
+
+// StringSlice returns the string list value of the supplied key of a
+// response data map. Vault returns some lists as comma separated strings,
+// which are split accordingly.
+func StringSlice(data map[string]interface{}, key string) []string {
+	switch v := data[key].(type) {
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	case []string:
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, ",")
+	default:
+		return nil
+	}
+}
+
+// Map returns the object value of the supplied key of a response data map.
+func Map(data map[string]interface{}, key string) map[string]interface{} {
+	m, _ := data[key].(map[string]interface{})
+	return m
+}
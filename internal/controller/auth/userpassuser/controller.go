@@ -0,0 +1,300 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package userpassuser
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/password"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/auth/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotUserpassUser = "managed resource is not a UserpassUser custom resource"
+	errNewClient       = "cannot create new Vault client"
+	errRead            = "cannot read userpass user"
+	errWrite           = "cannot write userpass user"
+	errDelete          = "cannot delete userpass user"
+	errGetPassword     = "cannot get password secret"
+	errGenerate        = "cannot generate password"
+)
+
+// Setup adds a controller that reconciles UserpassUser managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.UserpassUserGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.UserpassUserGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.UserpassUserGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.UserpassUserGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.UserpassUserGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.UserpassUser{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of UserpassUsers that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.UserpassUser); !ok {
+		return nil, errors.New(errNotUserpassUser)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{kube: c.kube, client: vc}, nil
+}
+
+type external struct {
+	kube   client.Client
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.UserpassUser)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotUserpassUser)
+	}
+	s, err := e.client.Read(ctx, cr.GetVaultPath())
+	if vault.IsNotFound(err) || (err == nil && s == nil) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRead)
+	}
+	conn := managed.ConnectionDetails{
+		xpv1.ResourceCredentialsSecretUserKey: []byte(cr.Spec.ForProvider.Username),
+	}
+	upToDate := isUpToDate(cr.Spec.ForProvider, s.Data)
+	// Vault never returns the password hash, so a referenced password is
+	// considered changed whenever its secret has been updated since we last
+	// wrote it.
+	if ref := cr.Spec.ForProvider.PasswordSecretRef; ref != nil {
+		pw, version, err := e.password(ctx, ref)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		upToDate = upToDate && version == cr.Status.AtProvider.PasswordSecretVersion
+		conn[xpv1.ResourceCredentialsSecretPasswordKey] = []byte(pw)
+	}
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  upToDate,
+		ConnectionDetails: conn,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.UserpassUser)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotUserpassUser)
+	}
+	var pw string
+	var err error
+	if ref := cr.Spec.ForProvider.PasswordSecretRef; ref != nil {
+		// The secret version cannot be recorded in the status during
+		// creation, so the password is written once more by the first
+		// update.
+		pw, _, err = e.password(ctx, ref)
+	} else {
+		pw, err = password.Generate()
+		err = errors.Wrap(err, errGenerate)
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	body := parameters(cr.Spec.ForProvider)
+	body["password"] = pw
+	if _, err := e.client.Write(ctx, cr.GetVaultPath(), body); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errWrite)
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Username)
+	return managed.ExternalCreation{
+		ExternalNameAssigned: true,
+		ConnectionDetails: managed.ConnectionDetails{
+			xpv1.ResourceCredentialsSecretUserKey:     []byte(cr.Spec.ForProvider.Username),
+			xpv1.ResourceCredentialsSecretPasswordKey: []byte(pw),
+		},
+	}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.UserpassUser)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotUserpassUser)
+	}
+	body := parameters(cr.Spec.ForProvider)
+	version := ""
+	if ref := cr.Spec.ForProvider.PasswordSecretRef; ref != nil {
+		pw, v, err := e.password(ctx, ref)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		body["password"] = pw
+		version = v
+	}
+	if _, err := e.client.Write(ctx, cr.GetVaultPath(), body); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errWrite)
+	}
+	cr.Status.AtProvider.PasswordSecretVersion = version
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.UserpassUser)
+	if !ok {
+		return errors.New(errNotUserpassUser)
+	}
+	err := e.client.Delete(ctx, cr.GetVaultPath())
+	return errors.Wrap(resource.Ignore(vault.IsNotFound, err), errDelete)
+}
+
+// password returns the referenced password and the resource version of the
+// secret it is stored in.
+func (e *external) password(ctx context.Context, ref *xpv1.SecretKeySelector) (string, string, error) {
+	s := &corev1.Secret{}
+	if err := clients.SecretReader(e.kube).Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", "", errors.Wrap(err, errGetPassword)
+	}
+	return string(s.Data[ref.Key]), s.GetResourceVersion(), nil
+}
+
+// parameters returns the request body for the supplied parameters, omitting
+// the password. Unset parameters are left to Vault's defaults.
+func parameters(p v1alpha1.UserpassUserParameters) map[string]interface{} {
+	body := map[string]interface{}{}
+	if p.TokenPolicies != nil {
+		body["token_policies"] = p.TokenPolicies
+	}
+	if p.TokenBoundCidrs != nil {
+		body["token_bound_cidrs"] = p.TokenBoundCidrs
+	}
+	if p.TokenExplicitMaxTTL != nil {
+		body["token_explicit_max_ttl"] = *p.TokenExplicitMaxTTL
+	}
+	if p.TokenMaxTTL != nil {
+		body["token_max_ttl"] = *p.TokenMaxTTL
+	}
+	if p.TokenNoDefaultPolicy != nil {
+		body["token_no_default_policy"] = *p.TokenNoDefaultPolicy
+	}
+	if p.TokenNumUses != nil {
+		body["token_num_uses"] = *p.TokenNumUses
+	}
+	if p.TokenPeriod != nil {
+		body["token_period"] = *p.TokenPeriod
+	}
+	if p.TokenTTL != nil {
+		body["token_ttl"] = *p.TokenTTL
+	}
+	if p.TokenType != nil {
+		body["token_type"] = *p.TokenType
+	}
+	return body
+}
+
+// isUpToDate returns true if the supplied user data read from Vault matches
+// the parameters that are set.
+func isUpToDate(p v1alpha1.UserpassUserParameters, data map[string]interface{}) bool { // nolint:gocyclo
+	// Every parameter is an independent, optional comparison so splitting
+	// this up would not make it easier to follow.
+	switch {
+	case p.TokenPolicies != nil && !equalSets(p.TokenPolicies, vault.StringSlice(data, "token_policies")):
+		return false
+	case p.TokenBoundCidrs != nil && !equalSets(p.TokenBoundCidrs, vault.StringSlice(data, "token_bound_cidrs")):
+		return false
+	case p.TokenExplicitMaxTTL != nil && *p.TokenExplicitMaxTTL != vault.Int64(data, "token_explicit_max_ttl"):
+		return false
+	case p.TokenMaxTTL != nil && *p.TokenMaxTTL != vault.Int64(data, "token_max_ttl"):
+		return false
+	case p.TokenNoDefaultPolicy != nil && *p.TokenNoDefaultPolicy != vault.Bool(data, "token_no_default_policy"):
+		return false
+	case p.TokenNumUses != nil && *p.TokenNumUses != vault.Int64(data, "token_num_uses"):
+		return false
+	case p.TokenPeriod != nil && *p.TokenPeriod != vault.Int64(data, "token_period"):
+		return false
+	case p.TokenTTL != nil && *p.TokenTTL != vault.Int64(data, "token_ttl"):
+		return false
+	case p.TokenType != nil && tokenType(*p.TokenType) != vault.String(data, "token_type"):
+		return false
+	}
+	return true
+}
+
+// tokenType returns the token type Vault reports for the supplied one.
+func tokenType(t string) string {
+	if t == "default" {
+		return "default-service"
+	}
+	return t
+}
+
+func equalSets(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -22,26 +22,41 @@ import (
 	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
 	tjcontroller "github.com/crossplane/terrajet/pkg/controller"
 	"github.com/crossplane/terrajet/pkg/terraform"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	v1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/generic/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/fingerprint"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/lock"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/workspace"
 )
 
 // Setup adds a controller that reconciles Secret managed resources.
-func Setup(mgr ctrl.Manager, o tjcontroller.Options) error {
+func Setup(mgr ctrl.Manager, o options.Options) error {
 	name := managed.ControllerName(v1alpha1.Secret_GroupVersionKind.String())
+	p := poll.New(mgr, xpresource.ManagedKind(v1alpha1.Secret_GroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, xpresource.ManagedKind(v1alpha1.Secret_GroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, xpresource.ManagedKind(v1alpha1.Secret_GroupVersionKind), name, o.ExpiryWarning)
+	ws := workspace.Wrap(o.WorkspaceStore)
+	c := workspace.NewBatchConnector(tjcontroller.NewConnector(clients.WithCachedSecrets(mgr.GetClient()), ws, o.SetupFn, o.Provider.Resources["vault_generic_secret"]), ws, o.Provider.Resources["vault_generic_secret"])
 	var initializers managed.InitializerChain
 	r := managed.NewReconciler(mgr,
 		xpresource.ManagedKind(v1alpha1.Secret_GroupVersionKind),
-		managed.WithExternalConnecter(tjcontroller.NewConnector(mgr.GetClient(), o.WorkspaceStore, o.SetupFn, o.Provider.Resources["vault_generic_secret"])),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(fingerprint.NewConnector(lock.NewConnector(c, o.PathLocks), mgr.GetClient(), clients.NewVaultClient, "vault_generic_secret")))),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		managed.WithFinalizer(terraform.NewWorkspaceFinalizer(o.WorkspaceStore, xpresource.NewAPIFinalizer(mgr.GetClient(), managed.FinalizerName))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithFinalizer(terraform.NewWorkspaceFinalizer(ws, xpresource.NewAPIFinalizer(mgr.GetClient(), managed.FinalizerName))),
 		managed.WithTimeout(3*time.Minute),
 		managed.WithInitializers(initializers),
 	)
@@ -50,5 +65,6 @@ func Setup(mgr ctrl.Manager, o tjcontroller.Options) error {
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
 		For(&v1alpha1.Secret{}).
-		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
 }
@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidctoken
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/identity/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotOIDCToken = "managed resource is not an OIDCToken custom resource"
+	errNewClient    = "cannot create new Vault client"
+	errGenerate     = "cannot generate OIDC token"
+	errNoToken      = "Vault returned no OIDC token"
+	errParseToken   = "cannot parse the claims of the OIDC token"
+
+	// keyToken is the connection secret key the signed token is written to.
+	keyToken = "token"
+	// keyClientID is the connection secret key of the audience of the token.
+	keyClientID = "client_id"
+
+	fmtTokenPath = "identity/oidc/token/%s"
+)
+
+// Setup adds a controller that reconciles OIDCToken managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.OIDCTokenGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.OIDCTokenGroupVersionKind), o.Options.Options, o.PollLimits, poll.WithDeadline(deadline))
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.OIDCTokenGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.OIDCTokenGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.OIDCTokenGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.OIDCToken{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of OIDCTokens that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.OIDCToken); !ok {
+		return nil, errors.New(errNotOIDCToken)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{client: vc, now: time.Now}, nil
+}
+
+type external struct {
+	client *vault.Client
+	now    func() time.Time
+}
+
+func (e *external) Observe(_ context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.OIDCToken)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotOIDCToken)
+	}
+	if meta.GetExternalName(cr) == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	// A token cannot be read back from Vault, so it is considered up to date
+	// as long as the last one we generated for the role is not due for
+	// refresh.
+	exp := cr.Status.AtProvider.ExpiresAt
+	upToDate := cr.Status.AtProvider.Role == cr.Spec.ForProvider.Role && exp != nil && e.now().Before(refreshAt(cr))
+	if exp != nil && e.now().Before(exp.Time) {
+		cr.SetConditions(xpv1.Available())
+	}
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+// Create only records the role as the external name. The token is generated
+// by the subsequent Update call because status changes made during Create
+// are not persisted.
+func (e *external) Create(_ context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.OIDCToken)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotOIDCToken)
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Role)
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.OIDCToken)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotOIDCToken)
+	}
+	conn, err := e.generate(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalUpdate{ConnectionDetails: conn}, nil
+}
+
+// Delete is a no-op because Vault does not support revoking OIDC tokens.
+// They become invalid once they expire.
+func (e *external) Delete(_ context.Context, mg resource.Managed) error {
+	if _, ok := mg.(*v1alpha1.OIDCToken); !ok {
+		return errors.New(errNotOIDCToken)
+	}
+	return nil
+}
+
+// generate requests a new token for the role of the supplied OIDCToken and
+// records its claims in the status.
+func (e *external) generate(ctx context.Context, cr *v1alpha1.OIDCToken) (managed.ConnectionDetails, error) {
+	s, err := e.client.Read(ctx, fmt.Sprintf(fmtTokenPath, cr.Spec.ForProvider.Role))
+	if err != nil {
+		return nil, errors.Wrap(err, errGenerate)
+	}
+	if s == nil || vault.String(s.Data, keyToken) == "" {
+		return nil, errors.New(errNoToken)
+	}
+	token := vault.String(s.Data, keyToken)
+	c, err := parseClaims(token)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseToken)
+	}
+	cr.Status.AtProvider = v1alpha1.OIDCTokenObservation{
+		Role:      cr.Spec.ForProvider.Role,
+		ClientID:  vault.String(s.Data, keyClientID),
+		IssuedAt:  &metav1.Time{Time: time.Unix(c.IssuedAt, 0)},
+		ExpiresAt: &metav1.Time{Time: time.Unix(c.ExpiresAt, 0)},
+	}
+	return managed.ConnectionDetails{
+		keyToken:    []byte(token),
+		keyClientID: []byte(cr.Status.AtProvider.ClientID),
+	}, nil
+}
+
+// refreshAt returns the time the token of the supplied OIDCToken should be
+// replaced at.
+func refreshAt(cr *v1alpha1.OIDCToken) time.Time {
+	obs := cr.Status.AtProvider
+	before := obs.ExpiresAt.Sub(timeOrZero(obs.IssuedAt)) / 3
+	if cr.Spec.ForProvider.RefreshBefore != nil {
+		before = cr.Spec.ForProvider.RefreshBefore.Duration
+	}
+	return obs.ExpiresAt.Add(-before)
+}
+
+// deadline returns the time the token of the supplied OIDCToken should be
+// replaced at, so that it is polled by then however long its poll interval
+// has grown.
+func deadline(mg resource.Managed) (time.Time, bool) {
+	cr, ok := mg.(*v1alpha1.OIDCToken)
+	if !ok || cr.Status.AtProvider.ExpiresAt == nil {
+		return time.Time{}, false
+	}
+	return refreshAt(cr), true
+}
+
+func timeOrZero(t *metav1.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.Time
+}
+
+type claims struct {
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+// parseClaims returns the iat and exp claims of the supplied JWT. The
+// signature is not verified since the token was just received from Vault.
+func parseClaims(token string) (claims, error) {
+	c := claims{}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return c, errors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return c, err
+	}
+	if c.ExpiresAt == 0 {
+		return c, errors.New("token has no exp claim")
+	}
+	return c, nil
+}
@@ -0,0 +1,425 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretcopy
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/kv/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotSecretCopy = "managed resource is not a SecretCopy custom resource"
+	errNewClient     = "cannot create new Vault client"
+	errPattern       = "invalid glob pattern"
+	errList          = "cannot list source secrets"
+	errGetOverride   = "cannot get override values secret"
+	errReadSource    = "cannot read source secret"
+	errReadCopy      = "cannot read copy"
+	errWrite         = "cannot write copy"
+	errDelete        = "cannot delete copy"
+	errCopy          = "cannot copy %d of %d secrets"
+	errConflict      = "destination holds a different secret that was not copied by this SecretCopy, set overwrite to replace it"
+)
+
+// Setup adds a controller that reconciles SecretCopy managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.SecretCopyGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.SecretCopyGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.SecretCopyGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.SecretCopyGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.SecretCopyGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.SecretCopy{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of SecretCopies that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{
+		kube:              kube,
+		newClientFn:       clients.NewVaultClient,
+		newConfigClientFn: clients.NewVaultClientForConfig,
+	}
+}
+
+type connector struct {
+	kube              client.Client
+	newClientFn       func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+	newConfigClientFn func(ctx context.Context, kube client.Client, name string) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	cr, ok := mg.(*v1alpha1.SecretCopy)
+	if !ok {
+		return nil, errors.New(errNotSecretCopy)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	src, err := c.location(ctx, vc, cr.Spec.ForProvider.Source)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := c.location(ctx, vc, cr.Spec.ForProvider.Destination)
+	if err != nil {
+		return nil, err
+	}
+	return &external{kube: c.kube, source: src, destination: dst}, nil
+}
+
+// location returns the supplied location with a client of its ProviderConfig
+// and namespace, which default to the ones of the supplied client.
+func (c *connector) location(ctx context.Context, vc *vault.Client, l v1alpha1.SecretCopyLocation) (*location, error) {
+	if ref := l.ProviderConfigReference; ref != nil {
+		var err error
+		if vc, err = c.newConfigClientFn(ctx, c.kube, ref.Name); err != nil {
+			return nil, errors.Wrap(err, errNewClient)
+		}
+	}
+	if l.Namespace != nil {
+		vc = vc.WithNamespace(*l.Namespace)
+	}
+	return &location{client: vc, spec: &l, version: l.GetKVVersion()}, nil
+}
+
+type external struct {
+	kube        client.Client
+	source      *location
+	destination *location
+}
+
+// A pending secret is a selected source secret that is not in sync with its
+// copy.
+type pending struct {
+	path          string
+	data          map[string]interface{}
+	sourceVersion int64
+	conflict      bool
+	err           error
+}
+
+// Observe never reports the copies as missing so that they are written by
+// Update, whose results the managed reconciler persists in the status unlike
+// the ones of Create.
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.SecretCopy)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotSecretCopy)
+	}
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: cr.Status.AtProvider.LastCopyTime != nil}, nil
+	}
+	_, todo, stale, err := e.diff(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if cr.Status.AtProvider.LastCopyTime != nil {
+		cr.SetConditions(xpv1.Available())
+	} else {
+		cr.SetConditions(xpv1.Creating())
+	}
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: cr.Status.AtProvider.LastCopyTime != nil && len(todo) == 0 && len(stale) == 0,
+	}, nil
+}
+
+func (e *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	// Observe always reports the copies as existing.
+	return managed.ExternalCreation{}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.SecretCopy)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotSecretCopy)
+	}
+	results, todo, stale, err := e.diff(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	for _, r := range stale {
+		if err := e.delete(ctx, r); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	failed := 0
+	for _, p := range todo {
+		r := e.write(ctx, p)
+		if r.Result == v1alpha1.SecretCopyResultFailed || r.Result == v1alpha1.SecretCopyResultConflict {
+			failed++
+		}
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	now := metav1.Now()
+	cr.Status.AtProvider.Paths = results
+	cr.Status.AtProvider.LastCopyTime = &now
+	if failed > 0 {
+		return managed.ExternalUpdate{}, errors.Errorf(errCopy, failed, len(todo))
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete deletes the copies the SecretCopy wrote. Secrets that were already
+// in sync or in conflict at the destination are left as they are.
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.SecretCopy)
+	if !ok {
+		return errors.New(errNotSecretCopy)
+	}
+	for _, r := range cr.Status.AtProvider.Paths {
+		if err := e.delete(ctx, r); err != nil {
+			return err
+		}
+	}
+	cr.Status.AtProvider = v1alpha1.SecretCopyObservation{}
+	return nil
+}
+
+// delete deletes the copy of the supplied result if the SecretCopy wrote it.
+func (e *external) delete(ctx context.Context, r v1alpha1.SecretCopyPathResult) error {
+	if !e.written(r) {
+		return nil
+	}
+	var version int64
+	if r.DestinationVersion != nil {
+		version = *r.DestinationVersion
+	}
+	return errors.Wrap(e.destination.delete(ctx, r.Path, version), errDelete)
+}
+
+// written returns true if the supplied result is of a copy the SecretCopy
+// wrote.
+func (e *external) written(r v1alpha1.SecretCopyPathResult) bool {
+	return r.IsWrittenTo(e.destination.spec)
+}
+
+// diff returns the results of the selected source secrets that need not be
+// copied, the ones that have to be copied and the results of the copies
+// whose source secrets are gone. Copies are only ever deleted in Continuous
+// mode, and secrets that were copied are not copied again in Once mode.
+// Secrets at the destination that the SecretCopy did not write are only
+// overwritten if it allows it.
+func (e *external) diff(ctx context.Context, cr *v1alpha1.SecretCopy) ([]v1alpha1.SecretCopyPathResult, []pending, []v1alpha1.SecretCopyPathResult, error) {
+	p := cr.Spec.ForProvider
+	for _, pattern := range append(append([]string{}, p.Include...), p.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, nil, nil, errors.Wrap(err, errPattern)
+		}
+	}
+	overrides, err := e.overrides(ctx, p.Overrides)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	paths, err := e.source.walk(ctx, "", func(dir string) bool { return matches(p.Exclude, dir) })
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, errList)
+	}
+	once := p.Mode == nil || *p.Mode == v1alpha1.SecretCopyModeOnce
+	overwrite := p.Overwrite != nil && *p.Overwrite
+	previous := map[string]v1alpha1.SecretCopyPathResult{}
+	for _, r := range cr.Status.AtProvider.Paths {
+		previous[r.Path] = r
+	}
+
+	var results []v1alpha1.SecretCopyPathResult
+	var todo []pending
+	selected := map[string]bool{}
+	for _, rel := range paths {
+		if (len(p.Include) > 0 && !matches(p.Include, rel)) || matches(p.Exclude, rel) {
+			continue
+		}
+		r, ok := previous[rel]
+		if ok && once && r.Result != v1alpha1.SecretCopyResultFailed && r.Result != v1alpha1.SecretCopyResultConflict {
+			selected[rel] = true
+			results = append(results, r)
+			continue
+		}
+		data, version, err := e.source.read(ctx, rel)
+		if err != nil {
+			todo = append(todo, pending{path: rel, err: errors.Wrap(err, errReadSource)})
+			selected[rel] = true
+			continue
+		}
+		if data == nil {
+			// The secret was deleted since it was listed.
+			continue
+		}
+		selected[rel] = true
+		want := make(map[string]interface{}, len(data)+len(overrides[rel]))
+		for k, v := range data {
+			want[k] = v
+		}
+		for k, v := range overrides[rel] {
+			want[k] = v
+		}
+		got, dv, err := e.destination.read(ctx, rel)
+		switch {
+		case err != nil:
+			todo = append(todo, pending{path: rel, err: errors.Wrap(err, errReadCopy)})
+		case got != nil && reflect.DeepEqual(want, got):
+			r := v1alpha1.SecretCopyPathResult{
+				Path:               rel,
+				Result:             v1alpha1.SecretCopyResultInSync,
+				SourceVersion:      version2(version),
+				DestinationVersion: version2(dv),
+			}
+			if e.written(previous[rel]) {
+				// The version that was written is kept so that deleting the
+				// copy leaves later versions written by others.
+				r.Result = v1alpha1.SecretCopyResultCopied
+				r.DestinationVersion = previous[rel].DestinationVersion
+			}
+			results = append(results, r)
+		case got != nil && !overwrite && !e.written(previous[rel]):
+			todo = append(todo, pending{path: rel, sourceVersion: version, conflict: true})
+		default:
+			todo = append(todo, pending{path: rel, data: want, sourceVersion: version})
+		}
+	}
+
+	var stale []v1alpha1.SecretCopyPathResult
+	for rel, r := range previous {
+		switch {
+		case selected[rel]:
+		case !once:
+			// Only the copies that were written are deleted, the other
+			// results are dropped.
+			if e.written(r) {
+				stale = append(stale, r)
+			}
+		case e.written(r) || r.Result == v1alpha1.SecretCopyResultInSync:
+			// Copies of secrets that are no longer selected are kept, and
+			// deleted with the SecretCopy.
+			results = append(results, r)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Path < stale[j].Path })
+	return results, todo, stale, nil
+}
+
+// write copies the supplied pending secret and returns the result.
+func (e *external) write(ctx context.Context, p pending) v1alpha1.SecretCopyPathResult {
+	r := v1alpha1.SecretCopyPathResult{Path: p.path, SourceVersion: version2(p.sourceVersion)}
+	if p.conflict {
+		r.Result = v1alpha1.SecretCopyResultConflict
+		r.Message = errConflict
+		return r
+	}
+	err := p.err
+	if err == nil {
+		var v int64
+		v, err = e.destination.write(ctx, p.path, p.data)
+		err = errors.Wrap(err, errWrite)
+		r.DestinationVersion = version2(v)
+	}
+	if err != nil {
+		r.Result = v1alpha1.SecretCopyResultFailed
+		r.Message = err.Error()
+		return r
+	}
+	r.Result = v1alpha1.SecretCopyResultCopied
+	return r
+}
+
+// overrides returns the values the supplied overrides set, by path relative
+// to the prefixes.
+func (e *external) overrides(ctx context.Context, list []v1alpha1.SecretCopyOverride) (map[string]map[string]interface{}, error) {
+	result := make(map[string]map[string]interface{}, len(list))
+	for _, o := range list {
+		s := &corev1.Secret{}
+		ref := o.ValuesSecretRef
+		if err := clients.SecretReader(e.kube).Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, errors.Wrap(err, errGetOverride)
+		}
+		rel := strings.Trim(o.Path, "/")
+		if result[rel] == nil {
+			result[rel] = map[string]interface{}{}
+		}
+		for k, v := range s.Data {
+			result[rel][k] = string(v)
+		}
+	}
+	return result, nil
+}
+
+// matches returns true if one of the supplied glob patterns matches the
+// supplied path or one of the directories it is in.
+func matches(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		for p := rel; ; {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+			i := strings.LastIndex(p, "/")
+			if i < 0 {
+				break
+			}
+			p = p[:i]
+		}
+	}
+	return false
+}
+
+// version2 returns a pointer to the supplied KV version 2 secret version, or
+// nil for KV version 1 secrets, which have none.
+func version2(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,469 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretcopy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/kv/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+// A kvSecret is a secret of a fake KV secrets engine. Version n is at index
+// n-1, and is nil if it was deleted before the secret was read.
+type kvSecret struct {
+	versions []map[string]interface{}
+	deleted  map[int64]bool
+}
+
+// fakeKV is a Vault server with a single KV secrets engine of either
+// version at the supplied mount.
+type fakeKV struct {
+	mu      sync.Mutex
+	mount   string
+	version int64
+	secrets map[string]*kvSecret
+	// fail lists the paths, relative to the mount, that cannot be read.
+	fail map[string]bool
+	// deleted records the deleted secrets as path@version, or path for KV
+	// version 1.
+	deleted []string
+}
+
+func newFakeKV(t *testing.T, mount string, version int64, secrets map[string][]map[string]interface{}, fail ...string) (*fakeKV, *location) {
+	t.Helper()
+	f := &fakeKV{mount: mount, version: version, secrets: map[string]*kvSecret{}, fail: map[string]bool{}}
+	for rel, versions := range secrets {
+		f.secrets[rel] = &kvSecret{versions: versions, deleted: map[int64]bool{}}
+	}
+	for _, rel := range fail {
+		f.fail[rel] = true
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	vc, err := vault.New(vault.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatalf("vault.New(...): %v", err)
+	}
+	return f, &location{client: vc, spec: &v1alpha1.SecretCopyLocation{Mount: mount, KVVersion: &version}, version: version}
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rest := strings.Trim(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/v1/"), f.mount), "/")
+	kind, rel := kindData, rest
+	if f.version != kvVersion1 {
+		kind, rel = rest, ""
+		if i := strings.Index(rest, "/"); i >= 0 {
+			kind, rel = rest[:i], rest[i+1:]
+		}
+	}
+	s := f.secrets[rel]
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list") == "true":
+		f.list(w, rel)
+	case r.Method == http.MethodGet && f.fail[rel]:
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"internal error"}})
+	case r.Method == http.MethodGet:
+		if s == nil || s.deleted[int64(len(s.versions))] || s.versions[len(s.versions)-1] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data := s.versions[len(s.versions)-1]
+		if f.version != kvVersion1 {
+			data = map[string]interface{}{"data": data, "metadata": map[string]interface{}{"version": len(s.versions)}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	case r.Method == http.MethodPost && kind == kindData:
+		body := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if f.version != kvVersion1 {
+			body = vault.Map(body, "data")
+		}
+		if s == nil {
+			s = &kvSecret{deleted: map[int64]bool{}}
+			f.secrets[rel] = s
+		}
+		s.versions = append(s.versions, body)
+		if f.version == kvVersion1 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"version": len(s.versions)}})
+	case r.Method == http.MethodPost && kind == kindDelete:
+		body := struct {
+			Versions []int64 `json:"versions"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, v := range body.Versions {
+			if s != nil {
+				s.deleted[v] = true
+			}
+			f.deleted = append(f.deleted, fmt.Sprintf("%s@%d", rel, v))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && f.version == kvVersion1:
+		delete(f.secrets, rel)
+		f.deleted = append(f.deleted, rel)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list responds with the immediate children of the supplied directory.
+func (f *fakeKV) list(w http.ResponseWriter, dir string) {
+	keys := map[string]bool{}
+	for rel := range f.secrets {
+		if dir != "" {
+			if !strings.HasPrefix(rel, dir+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(rel, dir+"/")
+		}
+		if i := strings.Index(rel, "/"); i >= 0 {
+			rel = rel[:i+1]
+		}
+		keys[rel] = true
+	}
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	list := make([]string, 0, len(keys))
+	for k := range keys {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": list}})
+}
+
+func data(kv ...string) map[string]interface{} {
+	m := map[string]interface{}{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		m[kv[i]] = kv[i+1]
+	}
+	return m
+}
+
+func versions(vs ...map[string]interface{}) []map[string]interface{} {
+	return vs
+}
+
+func version(v int64) *int64 {
+	return &v
+}
+
+// todo is a pending secret without its error, which is only compared for
+// whether there is one.
+type todo struct {
+	path          string
+	data          map[string]interface{}
+	sourceVersion int64
+	conflict      bool
+	failed        bool
+}
+
+func TestDiff(t *testing.T) {
+	continuous := v1alpha1.SecretCopyModeContinuous
+	overwrite := true
+
+	type want struct {
+		results []v1alpha1.SecretCopyPathResult
+		todo    []todo
+		stale   []v1alpha1.SecretCopyPathResult
+	}
+	cases := map[string]struct {
+		params      v1alpha1.SecretCopyParameters
+		source      map[string][]map[string]interface{}
+		sourceFail  []string
+		destination map[string][]map[string]interface{}
+		destFail    []string
+		kvVersion   int64
+		override    *corev1.Secret
+		previous    []v1alpha1.SecretCopyPathResult
+		want        want
+	}{
+		"NewSecret": {
+			source: map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			want: want{
+				todo: []todo{{path: "a", data: data("k", "v"), sourceVersion: 1}},
+			},
+		},
+		"IncludeAndExclude": {
+			params: v1alpha1.SecretCopyParameters{Include: []string{"apps/*"}, Exclude: []string{"apps/y"}},
+			source: map[string][]map[string]interface{}{
+				"a":        versions(data("k", "a")),
+				"apps/x/1": versions(data("k", "x")),
+				"apps/y/1": versions(data("k", "y")),
+			},
+			want: want{
+				todo: []todo{{path: "apps/x/1", data: data("k", "x"), sourceVersion: 1}},
+			},
+		},
+		"Overrides": {
+			params: v1alpha1.SecretCopyParameters{Overrides: []v1alpha1.SecretCopyOverride{{
+				Path:            "/a/",
+				ValuesSecretRef: xpv1.SecretReference{Namespace: "ns", Name: "values"},
+			}}},
+			source: map[string][]map[string]interface{}{"a": versions(data("k", "v", "env", "prod"))},
+			override: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "values"},
+				Data:       map[string][]byte{"env": []byte("staging")},
+			},
+			want: want{
+				todo: []todo{{path: "a", data: data("k", "v", "env", "staging"), sourceVersion: 1}},
+			},
+		},
+		"SourceDeletedSinceListed": {
+			source: map[string][]map[string]interface{}{"a": versions(data("k", "v"), nil)},
+			want:   want{},
+		},
+		"SourceUnreadable": {
+			source:     map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			sourceFail: []string{"a"},
+			want: want{
+				todo: []todo{{path: "a", failed: true}},
+			},
+		},
+		"CopyUnreadable": {
+			source:   map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			destFail: []string{"a"},
+			want: want{
+				todo: []todo{{path: "a", failed: true}},
+			},
+		},
+		"InSync": {
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "old"), data("k", "v"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			want: want{
+				results: []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultInSync, SourceVersion: version(2), DestinationVersion: version(1)}},
+			},
+		},
+		"InSyncKeepsWrittenVersion": {
+			params:      v1alpha1.SecretCopyParameters{Mode: &continuous},
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "v"), data("k", "v"))},
+			previous:    []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultCopied, SourceVersion: version(1), DestinationVersion: version(1)}},
+			want: want{
+				results: []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultCopied, SourceVersion: version(1), DestinationVersion: version(1)}},
+			},
+		},
+		"Conflict": {
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "other"))},
+			want: want{
+				todo: []todo{{path: "a", sourceVersion: 1, conflict: true}},
+			},
+		},
+		"ConflictOfCopyNotWritten": {
+			params:      v1alpha1.SecretCopyParameters{Mode: &continuous},
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "other"))},
+			previous:    []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultInSync, SourceVersion: version(1), DestinationVersion: version(1)}},
+			want: want{
+				todo: []todo{{path: "a", sourceVersion: 1, conflict: true}},
+			},
+		},
+		"Overwrite": {
+			params:      v1alpha1.SecretCopyParameters{Overwrite: &overwrite},
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "other"))},
+			want: want{
+				todo: []todo{{path: "a", data: data("k", "v"), sourceVersion: 1}},
+			},
+		},
+		"OverwriteWrittenCopy": {
+			params:      v1alpha1.SecretCopyParameters{Mode: &continuous},
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "v"), data("k", "new"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			previous:    []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultCopied, SourceVersion: version(1), DestinationVersion: version(1)}},
+			want: want{
+				todo: []todo{{path: "a", data: data("k", "new"), sourceVersion: 2}},
+			},
+		},
+		"OnceKeepsCopies": {
+			source:      map[string][]map[string]interface{}{"a": versions(data("k", "v"), data("k", "new"))},
+			destination: map[string][]map[string]interface{}{"a": versions(data("k", "v"))},
+			previous:    []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultCopied, SourceVersion: version(1), DestinationVersion: version(1)}},
+			want: want{
+				results: []v1alpha1.SecretCopyPathResult{{Path: "a", Result: v1alpha1.SecretCopyResultCopied, SourceVersion: version(1), DestinationVersion: version(1)}},
+			},
+		},
+		"OnceRetriesFailures": {
+			source: map[string][]map[string]interface{}{
+				"a": versions(data("k", "a")),
+				"b": versions(data("k", "b")),
+			},
+			previous: []v1alpha1.SecretCopyPathResult{
+				{Path: "a", Result: v1alpha1.SecretCopyResultFailed, Message: "boom"},
+				{Path: "b", Result: v1alpha1.SecretCopyResultConflict, Message: errConflict},
+			},
+			want: want{
+				todo: []todo{
+					{path: "a", data: data("k", "a"), sourceVersion: 1},
+					{path: "b", data: data("k", "b"), sourceVersion: 1},
+				},
+			},
+		},
+		"OnceKeepsUnselectedCopies": {
+			source: map[string][]map[string]interface{}{},
+			previous: []v1alpha1.SecretCopyPathResult{
+				{Path: "copied", Result: v1alpha1.SecretCopyResultCopied, DestinationVersion: version(3)},
+				{Path: "insync", Result: v1alpha1.SecretCopyResultInSync, DestinationVersion: version(1)},
+				{Path: "conflict", Result: v1alpha1.SecretCopyResultConflict},
+				{Path: "failed", Result: v1alpha1.SecretCopyResultFailed},
+			},
+			want: want{
+				results: []v1alpha1.SecretCopyPathResult{
+					{Path: "copied", Result: v1alpha1.SecretCopyResultCopied, DestinationVersion: version(3)},
+					{Path: "insync", Result: v1alpha1.SecretCopyResultInSync, DestinationVersion: version(1)},
+				},
+			},
+		},
+		"ContinuousDeletesStaleCopies": {
+			params: v1alpha1.SecretCopyParameters{Mode: &continuous},
+			source: map[string][]map[string]interface{}{},
+			previous: []v1alpha1.SecretCopyPathResult{
+				{Path: "copied", Result: v1alpha1.SecretCopyResultCopied, DestinationVersion: version(3)},
+				{Path: "unversioned", Result: v1alpha1.SecretCopyResultCopied},
+				{Path: "insync", Result: v1alpha1.SecretCopyResultInSync, DestinationVersion: version(1)},
+				{Path: "conflict", Result: v1alpha1.SecretCopyResultConflict},
+			},
+			want: want{
+				stale: []v1alpha1.SecretCopyPathResult{{Path: "copied", Result: v1alpha1.SecretCopyResultCopied, DestinationVersion: version(3)}},
+			},
+		},
+		"ContinuousDeletesStaleCopiesOfKVVersion1": {
+			params:    v1alpha1.SecretCopyParameters{Mode: &continuous},
+			source:    map[string][]map[string]interface{}{},
+			kvVersion: kvVersion1,
+			previous: []v1alpha1.SecretCopyPathResult{
+				{Path: "copied", Result: v1alpha1.SecretCopyResultCopied},
+				{Path: "insync", Result: v1alpha1.SecretCopyResultInSync},
+			},
+			want: want{
+				stale: []v1alpha1.SecretCopyPathResult{{Path: "copied", Result: v1alpha1.SecretCopyResultCopied}},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, source := newFakeKV(t, "src", 2, tc.source, tc.sourceFail...)
+			kv := tc.kvVersion
+			if kv == 0 {
+				kv = 2
+			}
+			_, destination := newFakeKV(t, "dst", kv, tc.destination, tc.destFail...)
+			b := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tc.override != nil {
+				b = b.WithObjects(tc.override)
+			}
+			e := &external{kube: b.Build(), source: source, destination: destination}
+			cr := &v1alpha1.SecretCopy{}
+			cr.Spec.ForProvider = tc.params
+			cr.Status.AtProvider.Paths = tc.previous
+
+			results, pending, stale, err := e.diff(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("diff(...): %v", err)
+			}
+			sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+			if !reflect.DeepEqual(results, tc.want.results) {
+				t.Errorf("diff(...): results %+v, want %+v", results, tc.want.results)
+			}
+			var got []todo
+			for _, p := range pending {
+				got = append(got, todo{path: p.path, data: p.data, sourceVersion: p.sourceVersion, conflict: p.conflict, failed: p.err != nil})
+			}
+			if !reflect.DeepEqual(got, tc.want.todo) {
+				t.Errorf("diff(...): todo %+v, want %+v", got, tc.want.todo)
+			}
+			if !reflect.DeepEqual(stale, tc.want.stale) {
+				t.Errorf("diff(...): stale %+v, want %+v", stale, tc.want.stale)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		kvVersion   int64
+		paths       []v1alpha1.SecretCopyPathResult
+		wantDeleted []string
+	}{
+		"KVVersion2": {
+			kvVersion: 2,
+			paths: []v1alpha1.SecretCopyPathResult{
+				{Path: "copied", Result: v1alpha1.SecretCopyResultCopied, DestinationVersion: version(1)},
+				{Path: "unversioned", Result: v1alpha1.SecretCopyResultCopied},
+				{Path: "insync", Result: v1alpha1.SecretCopyResultInSync, DestinationVersion: version(1)},
+				{Path: "conflict", Result: v1alpha1.SecretCopyResultConflict},
+				{Path: "failed", Result: v1alpha1.SecretCopyResultFailed},
+			},
+			wantDeleted: []string{"copied@1"},
+		},
+		"KVVersion1": {
+			kvVersion: kvVersion1,
+			paths: []v1alpha1.SecretCopyPathResult{
+				{Path: "copied", Result: v1alpha1.SecretCopyResultCopied},
+				{Path: "insync", Result: v1alpha1.SecretCopyResultInSync},
+			},
+			wantDeleted: []string{"copied"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Later versions of the copies were written by others.
+			secrets := map[string][]map[string]interface{}{}
+			for _, r := range tc.paths {
+				secrets[r.Path] = versions(data("k", "copy"), data("k", "other"))
+			}
+			dst, destination := newFakeKV(t, "dst", tc.kvVersion, secrets)
+			e := &external{destination: destination}
+			cr := &v1alpha1.SecretCopy{}
+			cr.Status.AtProvider.Paths = tc.paths
+
+			if err := e.Delete(context.Background(), cr); err != nil {
+				t.Fatalf("Delete(...): %v", err)
+			}
+			if !reflect.DeepEqual(dst.deleted, tc.wantDeleted) {
+				t.Errorf("Delete(...): deleted %v, want %v", dst.deleted, tc.wantDeleted)
+			}
+			if tc.kvVersion != kvVersion1 && dst.secrets["copied"].deleted[2] {
+				t.Errorf("Delete(...): deleted the version written by others")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretcopy
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/kv/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+const (
+	kvVersion1 = 1
+
+	kindData     = "data"
+	kindMetadata = "metadata"
+	kindDelete   = "delete"
+)
+
+// A location is a prefix of a KV secrets engine.
+type location struct {
+	client  *vault.Client
+	spec    *v1alpha1.SecretCopyLocation
+	version int64
+}
+
+// path returns the API path of the supplied kind, i.e. data or metadata, of
+// the supplied path relative to the prefix. KV version 1 has no kinds.
+func (l *location) path(kind, rel string) string {
+	return l.spec.GetKVPath(kind, rel)
+}
+
+// walk returns the paths relative to the prefix of the secrets below the
+// supplied directory. The directories skip returns true for are not walked.
+func (l *location) walk(ctx context.Context, dir string, skip func(dir string) bool) ([]string, error) {
+	keys, err := l.client.List(ctx, l.path(kindMetadata, dir))
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, k := range keys {
+		rel := k
+		if dir != "" {
+			rel = dir + "/" + k
+		}
+		if !strings.HasSuffix(k, "/") {
+			paths = append(paths, rel)
+			continue
+		}
+		sub := strings.TrimSuffix(rel, "/")
+		if skip(sub) {
+			continue
+		}
+		below, err := l.walk(ctx, sub, skip)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, below...)
+	}
+	return paths, nil
+}
+
+// read returns the data of the secret at the supplied path and its version,
+// which is 0 for KV version 1. The data is nil if there is no secret at the
+// path, or if its latest version is deleted.
+func (l *location) read(ctx context.Context, rel string) (map[string]interface{}, int64, error) {
+	s, err := l.client.Read(ctx, l.path(kindData, rel))
+	if vault.IsNotFound(err) || (err == nil && s == nil) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if l.version == kvVersion1 {
+		return s.Data, 0, nil
+	}
+	return vault.Map(s.Data, "data"), vault.Int64(vault.Map(s.Data, "metadata"), "version"), nil
+}
+
+// write writes the supplied data to the secret at the supplied path and
+// returns its new version, which is 0 for KV version 1.
+func (l *location) write(ctx context.Context, rel string, data map[string]interface{}) (int64, error) {
+	if l.version == kvVersion1 {
+		_, err := l.client.Write(ctx, l.path(kindData, rel), data)
+		return 0, err
+	}
+	s, err := l.client.Write(ctx, l.path(kindData, rel), map[string]interface{}{"data": data})
+	if err != nil || s == nil {
+		return 0, err
+	}
+	return vault.Int64(s.Data, "version"), nil
+}
+
+// delete deletes the supplied version of the secret at the supplied path,
+// so that later versions written by others are kept. KV version 2 secrets
+// are only soft deleted so that they can be undeleted, and KV version 1
+// secrets, which have no versions, are deleted as a whole.
+func (l *location) delete(ctx context.Context, rel string, version int64) error {
+	if l.version == kvVersion1 {
+		return resource.Ignore(vault.IsNotFound, l.client.Delete(ctx, l.path(kindData, rel)))
+	}
+	_, err := l.client.Write(ctx, l.path(kindDelete, rel), map[string]interface{}{"versions": []int64{version}})
+	return resource.Ignore(vault.IsNotFound, err)
+}
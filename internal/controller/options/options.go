@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options contains the options the controllers of the provider are
+// set up with.
+package options
+
+import (
+	"time"
+
+	tjcontroller "github.com/crossplane/terrajet/pkg/controller"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/lock"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+// Options contains the options of the controllers of the provider in
+// addition to the ones of Terrajet.
+type Options struct {
+	tjcontroller.Options
+
+	// PollLimits bound the intervals the controllers adapt the poll
+	// intervals of their managed resources within. Resources are polled at
+	// PollInterval if no limits are set.
+	PollLimits poll.Limits
+
+	// PathLocks serializes the operations of managed resources that write to
+	// the same Vault path with the same ProviderConfig across controllers.
+	// Operations are not serialized if it is nil.
+	PathLocks *lock.Manager
+
+	// ExpiryWarning is how long before managed resources expire a warning
+	// event is emitted.
+	ExpiryWarning time.Duration
+}
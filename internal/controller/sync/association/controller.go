@@ -0,0 +1,294 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package association
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sync/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotAssociation = "managed resource is not an Association custom resource"
+	errNewClient      = "cannot create new Vault client"
+	errReadMount      = "cannot read secrets engine mount"
+	errRead           = "cannot read secrets sync associations"
+	errSet            = "cannot associate secret with destination"
+	errRemove         = "cannot remove secret association from destination"
+	errImmutable      = "destinationType, destinationName, mount and secretName cannot be changed once the association is created, create a new Association instead"
+
+	fmtMountPath = "sys/mounts/%s"
+
+	statusSynced = "SYNCED"
+)
+
+// Setup adds a controller that reconciles Association managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.AssociationGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.AssociationGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.AssociationGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.AssociationGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.AssociationGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Association{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of Associations that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Association); !ok {
+		return nil, errors.New(errNotAssociation)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{client: vc}, nil
+}
+
+type external struct {
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Association)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAssociation)
+	}
+	// The parameters the association was created with are recorded in its
+	// external name. Changing them would leave that association behind, and
+	// removing it on update would only be possible while the old parameters
+	// are known. An Association that is being deleted is observed with them
+	// so that the association it created is removed.
+	p, created := parameters(cr)
+	if created && p != cr.Spec.ForProvider && !meta.WasDeleted(cr) {
+		return managed.ExternalObservation{}, errors.New(errImmutable)
+	}
+	// Associations are keyed by the accessor of the mount rather than its
+	// path.
+	m, err := e.client.Read(ctx, fmt.Sprintf(fmtMountPath, p.Mount))
+	if vault.IsNotFound(err) || (err == nil && m == nil) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errReadMount)
+	}
+	accessor := vault.String(m.Data, "accessor")
+	s, err := e.client.Read(ctx, vaultPath(p))
+	if vault.IsNotFound(err) || (err == nil && s == nil) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRead)
+	}
+	obs, unsynced, found := observe(vault.Map(s.Data, "associated_secrets"), accessor, p.SecretName)
+	if !found {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	cr.Status.AtProvider = obs
+	cr.SetConditions(xpv1.Available(), secretSynced(obs.SyncStatus, unsynced))
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Association)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotAssociation)
+	}
+	if _, err := e.client.Write(ctx, cr.GetVaultPath()+"/set", body(cr.Spec.ForProvider)); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errSet)
+	}
+	meta.SetExternalName(cr, externalName(cr.Spec.ForProvider))
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	// An association has nothing to update, its parameters are immutable; a
+	// changed secret is synced by Vault on its own.
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Association)
+	if !ok {
+		return errors.New(errNotAssociation)
+	}
+	p, _ := parameters(cr)
+	_, err := e.client.Write(ctx, vaultPath(p)+"/remove", body(p))
+	return errors.Wrap(resource.Ignore(vault.IsNotFound, err), errRemove)
+}
+
+// externalName returns the external name of the association with the
+// supplied parameters. They are escaped so that mounts and secret names that
+// contain slashes can be told apart. Names of Kubernetes objects, which are
+// the external names of associations that were not created yet, never
+// contain a slash.
+func externalName(p v1alpha1.AssociationParameters) string {
+	return strings.Join([]string{
+		url.PathEscape(p.DestinationType),
+		url.PathEscape(p.DestinationName),
+		url.PathEscape(p.Mount),
+		url.PathEscape(p.SecretName),
+	}, "/")
+}
+
+// parameters returns the parameters the supplied Association was created
+// with, as recorded in its external name, and true, or its current
+// parameters and false if it was not created yet.
+func parameters(cr *v1alpha1.Association) (v1alpha1.AssociationParameters, bool) {
+	parts := strings.Split(meta.GetExternalName(cr), "/")
+	if len(parts) != 4 {
+		return cr.Spec.ForProvider, false
+	}
+	for i := range parts {
+		v, err := url.PathUnescape(parts[i])
+		if err != nil {
+			return cr.Spec.ForProvider, false
+		}
+		parts[i] = v
+	}
+	return v1alpha1.AssociationParameters{
+		DestinationType: parts[0],
+		DestinationName: parts[1],
+		Mount:           parts[2],
+		SecretName:      parts[3],
+	}, true
+}
+
+// vaultPath returns the Vault API path of the associations of the
+// destination of the supplied parameters.
+func vaultPath(p v1alpha1.AssociationParameters) string {
+	a := &v1alpha1.Association{}
+	a.Spec.ForProvider = p
+	return a.GetVaultPath()
+}
+
+func body(p v1alpha1.AssociationParameters) map[string]interface{} {
+	return map[string]interface{}{
+		"mount":       p.Mount,
+		"secret_name": p.SecretName,
+	}
+}
+
+// observe returns the observation of the supplied secret from the associated
+// secrets of a destination. A secret synced key by key has an association per
+// key, in which case the secret is only reported as synced once all of its
+// keys are, and the keys that are not are returned as well.
+func observe(associated map[string]interface{}, accessor, secretName string) (v1alpha1.AssociationObservation, []string, bool) {
+	obs := v1alpha1.AssociationObservation{Accessor: accessor}
+	var unsynced []string
+	found := false
+	for _, v := range associated {
+		a, ok := v.(map[string]interface{})
+		if !ok || vault.String(a, "accessor") != accessor || vault.String(a, "secret_name") != secretName {
+			continue
+		}
+		found = true
+		status := vault.String(a, "sync_status")
+		if obs.SyncStatus == "" || obs.SyncStatus == statusSynced {
+			obs.SyncStatus = status
+		}
+		if k := vault.String(a, "sub_key"); k != "" && status != statusSynced {
+			unsynced = append(unsynced, k)
+		}
+		// Timestamps are RFC 3339 so they can be compared lexically.
+		if u := vault.String(a, "updated_at"); u > obs.UpdatedAt {
+			obs.UpdatedAt = u
+		}
+	}
+	sort.Strings(unsynced)
+	return obs, unsynced, found
+}
+
+// secretSynced returns a condition that reports the supplied sync status.
+func secretSynced(status string, unsynced []string) xpv1.Condition {
+	c := xpv1.Condition{
+		Type:               v1alpha1.TypeSecretSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason(status),
+	}
+	if status == statusSynced {
+		c.Status = corev1.ConditionTrue
+	}
+	if len(unsynced) > 0 {
+		c.Message = "keys not synced: " + strings.Join(unsynced, ", ")
+	}
+	return c
+}
+
+// reason converts a sync status such as EXTERNAL_SERVICE_ERROR to a
+// condition reason such as ExternalServiceError.
+func reason(status string) xpv1.ConditionReason {
+	if status == "" {
+		return "Unknown"
+	}
+	words := strings.Split(strings.ToLower(status), "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return xpv1.ConditionReason(strings.Join(words, ""))
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destination
+
+import (
+	"context"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sync/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotDestination  = "managed resource is not a Destination custom resource"
+	errNewClient       = "cannot create new Vault client"
+	errRead            = "cannot read secrets sync destination"
+	errWrite           = "cannot write secrets sync destination"
+	errDelete          = "cannot delete secrets sync destination"
+	errGetCredentials  = "cannot get credentials secret"
+	errEmptyCredential = "credentials secret has no data"
+)
+
+// Setup adds a controller that reconciles Destination managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.DestinationGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.DestinationGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.DestinationGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.DestinationGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.DestinationGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Destination{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of Destinations that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Destination); !ok {
+		return nil, errors.New(errNotDestination)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{kube: c.kube, client: vc}, nil
+}
+
+type external struct {
+	kube   client.Client
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Destination)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotDestination)
+	}
+	s, err := e.client.Read(ctx, cr.GetVaultPath())
+	if vault.IsNotFound(err) || (err == nil && s == nil) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRead)
+	}
+	upToDate := isUpToDate(cr.Spec.ForProvider, s.Data)
+	// Vault masks the sensitive connection details, so the credentials are
+	// considered changed whenever their secret has been updated since we
+	// last wrote them.
+	if ref := cr.Spec.ForProvider.CredentialsSecretRef; ref != nil {
+		_, version, err := e.credentials(ctx, ref)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		upToDate = upToDate && version == cr.Status.AtProvider.CredentialsSecretVersion
+	}
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Destination)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotDestination)
+	}
+	// The secret version cannot be recorded in the status during creation,
+	// so the credentials are written once more by the first update.
+	body, _, err := e.body(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if _, err := e.client.Write(ctx, cr.GetVaultPath(), body); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errWrite)
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Name)
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Destination)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotDestination)
+	}
+	body, version, err := e.body(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if _, err := e.client.Patch(ctx, cr.GetVaultPath(), body); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errWrite)
+	}
+	cr.Status.AtProvider.CredentialsSecretVersion = version
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Destination)
+	if !ok {
+		return errors.New(errNotDestination)
+	}
+	err := e.client.Delete(ctx, cr.GetVaultPath())
+	return errors.Wrap(resource.Ignore(vault.IsNotFound, err), errDelete)
+}
+
+// body returns the request body for the supplied parameters, including the
+// referenced credentials, and the resource version of the credentials secret.
+func (e *external) body(ctx context.Context, p v1alpha1.DestinationParameters) (map[string]interface{}, string, error) {
+	body := map[string]interface{}{}
+	for k, v := range p.ConnectionParameters {
+		body[k] = v
+	}
+	version := ""
+	if ref := p.CredentialsSecretRef; ref != nil {
+		creds, v, err := e.credentials(ctx, ref)
+		if err != nil {
+			return nil, "", err
+		}
+		for k, v := range creds {
+			body[k] = string(v)
+		}
+		version = v
+	}
+	if p.SecretNameTemplate != nil {
+		body["secret_name_template"] = *p.SecretNameTemplate
+	}
+	if p.Granularity != nil {
+		body["granularity"] = *p.Granularity
+	}
+	if p.CustomTags != nil {
+		body["custom_tags"] = p.CustomTags
+	}
+	return body, version, nil
+}
+
+// credentials returns the data of the referenced credentials secret and its
+// resource version.
+func (e *external) credentials(ctx context.Context, ref *xpv1.SecretReference) (map[string][]byte, string, error) {
+	s := &corev1.Secret{}
+	if err := clients.SecretReader(e.kube).Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, "", errors.Wrap(err, errGetCredentials)
+	}
+	if len(s.Data) == 0 {
+		return nil, "", errors.New(errEmptyCredential)
+	}
+	return s.Data, s.GetResourceVersion(), nil
+}
+
+// isUpToDate returns true if the supplied destination data read from Vault
+// matches the parameters that are set. Sensitive connection details are
+// masked by Vault and compared through the credentials secret version instead.
+func isUpToDate(p v1alpha1.DestinationParameters, data map[string]interface{}) bool {
+	details := vault.Map(data, "connection_details")
+	for k, v := range p.ConnectionParameters {
+		if vault.String(details, k) != v {
+			return false
+		}
+	}
+	options := vault.Map(data, "options")
+	switch {
+	case p.SecretNameTemplate != nil && *p.SecretNameTemplate != vault.String(options, "secret_name_template"):
+		return false
+	case p.Granularity != nil && *p.Granularity != vault.String(options, "granularity_level"):
+		return false
+	case p.CustomTags != nil && !equalTags(p.CustomTags, vault.Map(options, "custom_tags")):
+		return false
+	}
+	return true
+}
+
+func equalTags(want map[string]string, got map[string]interface{}) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for k, v := range want {
+		if vault.String(got, k) != v {
+			return false
+		}
+	}
+	return true
+}
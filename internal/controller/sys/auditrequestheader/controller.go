@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditrequestheader
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotAuditRequestHeader = "managed resource is not an AuditRequestHeader custom resource"
+	errNewClient             = "cannot create new Vault client"
+	errRead                  = "cannot read audited request header"
+	errWrite                 = "cannot write audited request header"
+	errDelete                = "cannot delete audited request header"
+)
+
+// Setup adds a controller that reconciles AuditRequestHeader managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.AuditRequestHeaderGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.AuditRequestHeaderGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.AuditRequestHeaderGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.AuditRequestHeaderGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.AuditRequestHeaderGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.AuditRequestHeader{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of AuditRequestHeaders that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.AuditRequestHeader); !ok {
+		return nil, errors.New(errNotAuditRequestHeader)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{client: vc}, nil
+}
+
+type external struct {
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.AuditRequestHeader)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAuditRequestHeader)
+	}
+	s, err := e.client.Read(ctx, cr.GetVaultPath())
+	if vault.IsNotFound(err) || (err == nil && s == nil) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRead)
+	}
+	h, ok := header(s.Data, cr.Spec.ForProvider.Name)
+	if !ok {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	hmac := cr.Spec.ForProvider.HMAC
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: hmac == nil || *hmac == vault.Bool(h, "hmac"),
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.AuditRequestHeader)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotAuditRequestHeader)
+	}
+	if err := e.write(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Name)
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.AuditRequestHeader)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotAuditRequestHeader)
+	}
+	return managed.ExternalUpdate{}, e.write(ctx, cr)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.AuditRequestHeader)
+	if !ok {
+		return errors.New(errNotAuditRequestHeader)
+	}
+	err := e.client.Delete(ctx, cr.GetVaultPath())
+	return errors.Wrap(resource.Ignore(vault.IsNotFound, err), errDelete)
+}
+
+func (e *external) write(ctx context.Context, cr *v1alpha1.AuditRequestHeader) error {
+	body := map[string]interface{}{}
+	if cr.Spec.ForProvider.HMAC != nil {
+		body["hmac"] = *cr.Spec.ForProvider.HMAC
+	}
+	_, err := e.client.Write(ctx, cr.GetVaultPath(), body)
+	return errors.Wrap(err, errWrite)
+}
+
+// header returns the configuration of the supplied header from the response
+// data, which is keyed by the header name as Vault canonicalized it.
+func header(data map[string]interface{}, name string) (map[string]interface{}, bool) {
+	for k, v := range data {
+		if strings.EqualFold(k, name) {
+			h, ok := v.(map[string]interface{})
+			return h, ok
+		}
+	}
+	return nil, false
+}
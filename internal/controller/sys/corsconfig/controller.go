@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package corsconfig
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotCORSConfig = "managed resource is not a CORSConfig custom resource"
+	errNewClient     = "cannot create new Vault client"
+	errRead          = "cannot read CORS configuration"
+	errWrite         = "cannot write CORS configuration"
+	errDelete        = "cannot delete CORS configuration"
+)
+
+// Setup adds a controller that reconciles CORSConfig managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.CORSConfigGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.CORSConfigGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.CORSConfigGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.CORSConfigGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.CORSConfigGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.CORSConfig{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of CORSConfigs that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.CORSConfig); !ok {
+		return nil, errors.New(errNotCORSConfig)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{client: vc}, nil
+}
+
+type external struct {
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.CORSConfig)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCORSConfig)
+	}
+	s, err := e.client.Read(ctx, cr.GetVaultPath())
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRead)
+	}
+	// The CORS configuration always exists, deleting it disables CORS.
+	if s == nil || !vault.Bool(s.Data, "enabled") {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	p := cr.Spec.ForProvider
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		// Vault always allows a set of headers on top of the configured ones,
+		// so only the configured ones are checked for.
+		ResourceUpToDate: equalSets(p.AllowedOrigins, vault.StringSlice(s.Data, "allowed_origins")) &&
+			subset(p.AllowedHeaders, vault.StringSlice(s.Data, "allowed_headers")),
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.CORSConfig)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCORSConfig)
+	}
+	if err := e.write(ctx, cr); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	meta.SetExternalName(cr, "cors")
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.CORSConfig)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCORSConfig)
+	}
+	return managed.ExternalUpdate{}, e.write(ctx, cr)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.CORSConfig)
+	if !ok {
+		return errors.New(errNotCORSConfig)
+	}
+	return errors.Wrap(e.client.Delete(ctx, cr.GetVaultPath()), errDelete)
+}
+
+func (e *external) write(ctx context.Context, cr *v1alpha1.CORSConfig) error {
+	p := cr.Spec.ForProvider
+	body := map[string]interface{}{"allowed_origins": p.AllowedOrigins}
+	if p.AllowedHeaders != nil {
+		body["allowed_headers"] = p.AllowedHeaders
+	}
+	_, err := e.client.Write(ctx, cr.GetVaultPath(), body)
+	return errors.Wrap(err, errWrite)
+}
+
+func equalSets(a, b []string) bool {
+	return len(a) == len(b) && subset(a, b)
+}
+
+// subset returns true if every element of a is in b. Elements are compared
+// case insensitively since both origins and header names are.
+func subset(a, b []string) bool {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedscope
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	kvv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/kv/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/lock"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotManagedScope = "managed resource is not a ManagedScope custom resource"
+	errNewClient       = "cannot create new Vault client"
+	errPattern         = "invalid glob pattern"
+	errList            = "cannot list Vault objects"
+	errListManaged     = "cannot list managed resources"
+	errPrune           = "cannot delete unowned Vault object"
+
+	rootGroup = "vault.jet.crossplane.io"
+
+	defaultGracePeriod = 24 * time.Hour
+)
+
+var (
+	metricUnowned = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "managed_scope",
+		Name:      "unowned_objects",
+		Help:      "Number of Vault objects under the prefixes of a ManagedScope that are not backed by a managed resource.",
+	}, []string{"scope"})
+	metricPruned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "managed_scope",
+		Name:      "pruned_objects_total",
+		Help:      "Number of Vault objects a ManagedScope deleted because they were not backed by a managed resource.",
+	}, []string{"scope"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricUnowned, metricPruned)
+}
+
+// vaultPathser is implemented by the managed resources that write to more
+// than one Vault path.
+type vaultPathser interface {
+	GetVaultPaths() []string
+}
+
+// Setup adds a controller that reconciles ManagedScope managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.ManagedScopeGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.ManagedScopeGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.ManagedScopeGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.ManagedScopeGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ManagedScopeGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ManagedScope{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of ManagedScopes that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.ManagedScope); !ok {
+		return nil, errors.New(errNotManagedScope)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{kube: c.kube, client: vc}, nil
+}
+
+type external struct {
+	kube   client.Client
+	client *vault.Client
+}
+
+// Observe lists the prefixes and records the unowned objects in the status.
+// A ManagedScope is only out of date if unowned objects are due to be
+// deleted.
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ManagedScope)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotManagedScope)
+	}
+	if meta.WasDeleted(cr) {
+		metricUnowned.DeleteLabelValues(cr.GetName())
+		metricPruned.DeleteLabelValues(cr.GetName())
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	due, err := e.scan(ctx, cr, time.Now())
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	cr.SetConditions(xpv1.Available())
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: len(due) == 0,
+	}, nil
+}
+
+func (e *external) Create(_ context.Context, _ resource.Managed) (managed.ExternalCreation, error) {
+	// Observe always reports the ManagedScope as existing.
+	return managed.ExternalCreation{}, nil
+}
+
+// Update deletes the unowned objects whose grace period has passed. They
+// are listed anew so that objects that got a managed resource since they
+// were observed are kept.
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ManagedScope)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotManagedScope)
+	}
+	due, err := e.scan(ctx, cr, time.Now())
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	pruned := map[string]bool{}
+	defer func() {
+		unowned := cr.Status.AtProvider.Unowned[:0]
+		for _, o := range cr.Status.AtProvider.Unowned {
+			if !pruned[o.Path] {
+				unowned = append(unowned, o)
+			}
+		}
+		cr.Status.AtProvider.Unowned = unowned
+		cr.Status.AtProvider.UnownedObjects = int64(len(unowned))
+		cr.Status.AtProvider.PrunedObjects += int64(len(pruned))
+		metricUnowned.WithLabelValues(cr.GetName()).Set(float64(len(unowned)))
+		metricPruned.WithLabelValues(cr.GetName()).Add(float64(len(pruned)))
+	}()
+	for _, p := range due {
+		if err := e.client.Delete(ctx, p); resource.Ignore(vault.IsNotFound, err) != nil {
+			return managed.ExternalUpdate{}, errors.Wrapf(err, "%s %s", errPrune, p)
+		}
+		pruned[p] = true
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete deletes no Vault objects.
+func (e *external) Delete(_ context.Context, _ resource.Managed) error {
+	return nil
+}
+
+// scan lists the objects under the prefixes of the supplied ManagedScope,
+// records the ones that are not backed by a managed resource in its status
+// and returns the ones that are due to be deleted. Nothing is due while the
+// paths of some managed resources are not known.
+func (e *external) scan(ctx context.Context, cr *v1alpha1.ManagedScope, now time.Time) ([]string, error) {
+	owned, unresolved, err := e.owned(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+	objects := map[string]bool{}
+	for _, prefix := range cr.Spec.ForProvider.Prefixes {
+		dir, pattern := path.Split(strings.Trim(prefix.Path, "/"))
+		dir = strings.TrimSuffix(dir, "/")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrap(err, errPattern)
+		}
+		list := dir
+		if prefix.ListPath != nil {
+			list = strings.Trim(*prefix.ListPath, "/")
+		}
+		if err := e.walk(ctx, list, dir, pattern, objects); err != nil {
+			return nil, errors.Wrap(err, errList)
+		}
+	}
+
+	p := cr.Spec.ForProvider
+	// Objects may belong to the managed resources whose paths are not known,
+	// so none are deleted while there are any.
+	prune := p.Prune != nil && *p.Prune && len(unresolved) == 0
+	grace := defaultGracePeriod
+	if p.GracePeriod != nil {
+		grace = p.GracePeriod.Duration
+	}
+	firstSeen := map[string]metav1.Time{}
+	for _, o := range cr.Status.AtProvider.Unowned {
+		firstSeen[o.Path] = o.FirstSeen
+	}
+	var unowned []v1alpha1.ManagedScopeObject
+	var due []string
+	var ownedObjects int64
+	for o := range objects {
+		if owned[o] {
+			ownedObjects++
+			continue
+		}
+		seen, ok := firstSeen[o]
+		if !ok {
+			seen = metav1.NewTime(now)
+		}
+		u := v1alpha1.ManagedScopeObject{Path: o, FirstSeen: seen}
+		if prune {
+			after := metav1.NewTime(seen.Add(grace))
+			u.PruneAfter = &after
+			if !now.Before(after.Time) {
+				due = append(due, o)
+			}
+		}
+		unowned = append(unowned, u)
+	}
+	sort.Slice(unowned, func(i, j int) bool { return unowned[i].Path < unowned[j].Path })
+	sort.Strings(due)
+
+	t := metav1.NewTime(now)
+	cr.Status.AtProvider.Unowned = unowned
+	cr.Status.AtProvider.UnownedObjects = int64(len(unowned))
+	cr.Status.AtProvider.OwnedObjects = ownedObjects
+	cr.Status.AtProvider.UnresolvedResources = unresolved
+	cr.Status.AtProvider.LastScanTime = &t
+	metricUnowned.WithLabelValues(cr.GetName()).Set(float64(len(unowned)))
+	return due, nil
+}
+
+// walk adds the objects listed at the supplied list path whose names match
+// the supplied pattern to the supplied set, as paths in the supplied
+// directory. Directories that match are walked with every object below them.
+func (e *external) walk(ctx context.Context, list, dir, pattern string, objects map[string]bool) error {
+	keys, err := e.client.List(ctx, list)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		name := strings.TrimSuffix(k, "/")
+		if ok, _ := path.Match(pattern, name); pattern != "" && !ok {
+			continue
+		}
+		if strings.HasSuffix(k, "/") {
+			if err := e.walk(ctx, list+"/"+name, dir+"/"+name, "", objects); err != nil {
+				return err
+			}
+			continue
+		}
+		objects[strings.TrimPrefix(dir+"/"+name, "/")] = true
+	}
+	return nil
+}
+
+// owned returns the Vault paths of the managed resources of every kind of
+// the provider that write to the Vault of the supplied ManagedScope, and the
+// names of the ones whose paths are not known.
+func (e *external) owned(ctx context.Context, cr *v1alpha1.ManagedScope) (map[string]bool, []string, error) {
+	pc := providerConfig(cr)
+	s := e.kube.Scheme()
+	kinds := make([]schema.GroupVersionKind, 0)
+	for gvk := range s.AllKnownTypes() {
+		if strings.HasSuffix(gvk.Group, rootGroup) && strings.HasSuffix(gvk.Kind, "List") {
+			kinds = append(kinds, gvk)
+		}
+	}
+	owned := map[string]bool{}
+	var unresolved []string
+	for _, gvk := range kinds {
+		o, err := s.New(gvk)
+		if err != nil {
+			continue
+		}
+		l, ok := o.(resource.ManagedList)
+		if !ok {
+			continue
+		}
+		if err := e.kube.List(ctx, l); err != nil {
+			return nil, nil, errors.Wrapf(err, "%s %s", errListManaged, gvk.Kind)
+		}
+		for _, mg := range l.GetItems() {
+			if providerConfig(mg) != pc {
+				continue
+			}
+			ps, ok := paths(mg)
+			if !ok {
+				unresolved = append(unresolved, strings.TrimSuffix(gvk.Kind, "List")+"/"+mg.GetName())
+				continue
+			}
+			for _, p := range ps {
+				p = strings.Trim(p, "/")
+				owned[p] = true
+				// KV version 2 secrets are listed and deleted at their
+				// metadata path.
+				if mount, rel, ok := cut(p, "/data/"); ok {
+					owned[mount+"/metadata/"+rel] = true
+				}
+			}
+		}
+	}
+	sort.Strings(unresolved)
+	return owned, unresolved, nil
+}
+
+// providerConfig returns the name of the ProviderConfig of the Vault the
+// supplied managed resource writes to.
+func providerConfig(mg resource.Managed) string {
+	if sc, ok := mg.(*kvv1alpha1.SecretCopy); ok {
+		if ref := sc.Spec.ForProvider.Destination.ProviderConfigReference; ref != nil {
+			return ref.Name
+		}
+	}
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		return ref.Name
+	}
+	return ""
+}
+
+// paths returns the Vault paths the supplied managed resource writes to, and
+// false if they are not known.
+func paths(mg resource.Managed) ([]string, bool) {
+	if o, ok := mg.(vaultPathser); ok {
+		return o.GetVaultPaths(), true
+	}
+	p, ok := lock.VaultPath(mg)
+	if !ok || p == "" {
+		return nil, ok
+	}
+	return []string{p}, true
+}
+
+// cut slices the supplied string around the first instance of the supplied
+// separator.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managedscope
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis"
+	genericv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/generic/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+// fakeVault is a Vault server that stores the paths of objects, lists the
+// immediate children of a path and deletes objects.
+type fakeVault struct {
+	mu      sync.Mutex
+	objects map[string]bool
+	deleted []string
+}
+
+func newFakeVault(t *testing.T, objects ...string) (*fakeVault, *vault.Client) {
+	t.Helper()
+	f := &fakeVault{objects: map[string]bool{}}
+	for _, o := range objects {
+		f.objects[o] = true
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	vc, err := vault.New(vault.Config{Address: srv.URL})
+	if err != nil {
+		t.Fatalf("vault.New(...): %v", err)
+	}
+	return f, vc
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list") == "true":
+		keys := map[string]bool{}
+		for o := range f.objects {
+			rel := strings.TrimPrefix(o, p+"/")
+			if rel == o {
+				continue
+			}
+			if i := strings.Index(rel, "/"); i >= 0 {
+				rel = rel[:i+1]
+			}
+			keys[rel] = true
+		}
+		if len(keys) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		list := make([]string, 0, len(keys))
+		for k := range keys {
+			list = append(list, k)
+		}
+		sort.Strings(list)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": list}})
+	case r.Method == http.MethodDelete:
+		delete(f.objects, p)
+		f.deleted = append(f.deleted, p)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newKube(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	s := runtime.NewScheme()
+	if err := apis.AddToScheme(s); err != nil {
+		t.Fatalf("apis.AddToScheme(...): %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+// secret returns a generic Secret that writes to the supplied path with the
+// supplied ProviderConfig. Its path is not known if it is empty.
+func secret(name, path, providerConfig string) *genericv1alpha1.Secret {
+	s := &genericv1alpha1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if path != "" {
+		s.Spec.ForProvider.Path = &path
+	}
+	if providerConfig != "" {
+		s.Spec.ProviderConfigReference = &xpv1.Reference{Name: providerConfig}
+	}
+	return s
+}
+
+func scope(prune bool, grace time.Duration, prefixes ...v1alpha1.ManagedScopePrefix) *v1alpha1.ManagedScope {
+	cr := &v1alpha1.ManagedScope{ObjectMeta: metav1.ObjectMeta{Name: "scope"}}
+	cr.Spec.ForProvider.Prefixes = prefixes
+	cr.Spec.ForProvider.Prune = &prune
+	cr.Spec.ForProvider.GracePeriod = &metav1.Duration{Duration: grace}
+	return cr
+}
+
+func prefix(path string) v1alpha1.ManagedScopePrefix {
+	return v1alpha1.ManagedScopePrefix{Path: path}
+}
+
+func TestScan(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) metav1.Time { return metav1.NewTime(now.Add(d)) }
+	after := func(d time.Duration) *metav1.Time { t := at(d); return &t }
+	listPath := "sys/policy"
+
+	type want struct {
+		unowned    []v1alpha1.ManagedScopeObject
+		owned      int64
+		unresolved []string
+		due        []string
+	}
+	cases := map[string]struct {
+		objects  []string
+		managed  []client.Object
+		cr       *v1alpha1.ManagedScope
+		previous []v1alpha1.ManagedScopeObject
+		want     want
+	}{
+		"KVVersion2OwnedByDataPath": {
+			objects: []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+			managed: []client.Object{secret("a", "secret/data/apps/a", "")},
+			cr:      scope(false, time.Hour, prefix("secret/metadata/apps")),
+			want: want{
+				unowned: []v1alpha1.ManagedScopeObject{{Path: "secret/metadata/apps/b", FirstSeen: at(0)}},
+				owned:   1,
+			},
+		},
+		"OtherProviderConfig": {
+			objects: []string{"secret/metadata/apps/a"},
+			managed: []client.Object{secret("a", "secret/data/apps/a", "other")},
+			cr:      scope(false, time.Hour, prefix("secret/metadata/apps")),
+			want: want{
+				unowned: []v1alpha1.ManagedScopeObject{{Path: "secret/metadata/apps/a", FirstSeen: at(0)}},
+			},
+		},
+		"GlobWalksMatchingDirectories": {
+			objects: []string{"secret/metadata/apps/a/x", "secret/metadata/apps/a/y/z", "secret/metadata/apps/b", "secret/metadata/other/c"},
+			cr:      scope(false, time.Hour, prefix("secret/metadata/apps/a*")),
+			want: want{
+				unowned: []v1alpha1.ManagedScopeObject{
+					{Path: "secret/metadata/apps/a/x", FirstSeen: at(0)},
+					{Path: "secret/metadata/apps/a/y/z", FirstSeen: at(0)},
+				},
+			},
+		},
+		"GlobWithListPath": {
+			objects: []string{"sys/policy/team-a", "sys/policy/team-b", "sys/policy/default"},
+			managed: []client.Object{secret("a", "sys/policies/acl/team-a", "")},
+			cr: scope(false, time.Hour, v1alpha1.ManagedScopePrefix{
+				Path:     "sys/policies/acl/team-*",
+				ListPath: &listPath,
+			}),
+			want: want{
+				unowned: []v1alpha1.ManagedScopeObject{{Path: "sys/policies/acl/team-b", FirstSeen: at(0)}},
+				owned:   1,
+			},
+		},
+		"FirstSeenCarriedOver": {
+			objects:  []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+			cr:       scope(true, time.Hour, prefix("secret/metadata/apps")),
+			previous: []v1alpha1.ManagedScopeObject{{Path: "secret/metadata/apps/a", FirstSeen: at(-30 * time.Minute)}},
+			want: want{
+				unowned: []v1alpha1.ManagedScopeObject{
+					{Path: "secret/metadata/apps/a", FirstSeen: at(-30 * time.Minute), PruneAfter: after(30 * time.Minute)},
+					{Path: "secret/metadata/apps/b", FirstSeen: at(0), PruneAfter: after(time.Hour)},
+				},
+			},
+		},
+		"GracePeriodPassed": {
+			objects: []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+			cr:      scope(true, time.Hour, prefix("secret/metadata/apps")),
+			previous: []v1alpha1.ManagedScopeObject{
+				{Path: "secret/metadata/apps/a", FirstSeen: at(-time.Hour)},
+				{Path: "secret/metadata/apps/gone", FirstSeen: at(-2 * time.Hour)},
+			},
+			want: want{
+				unowned: []v1alpha1.ManagedScopeObject{
+					{Path: "secret/metadata/apps/a", FirstSeen: at(-time.Hour), PruneAfter: after(0)},
+					{Path: "secret/metadata/apps/b", FirstSeen: at(0), PruneAfter: after(time.Hour)},
+				},
+				due: []string{"secret/metadata/apps/a"},
+			},
+		},
+		"NoPruningWhileUnresolved": {
+			objects:  []string{"secret/metadata/apps/a"},
+			managed:  []client.Object{secret("unknown", "", "")},
+			cr:       scope(true, time.Hour, prefix("secret/metadata/apps")),
+			previous: []v1alpha1.ManagedScopeObject{{Path: "secret/metadata/apps/a", FirstSeen: at(-2 * time.Hour)}},
+			want: want{
+				unowned:    []v1alpha1.ManagedScopeObject{{Path: "secret/metadata/apps/a", FirstSeen: at(-2 * time.Hour)}},
+				unresolved: []string{"Secret/unknown"},
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, vc := newFakeVault(t, tc.objects...)
+			e := &external{kube: newKube(t, tc.managed...), client: vc}
+			tc.cr.Status.AtProvider.Unowned = tc.previous
+			due, err := e.scan(context.Background(), tc.cr, now)
+			if err != nil {
+				t.Fatalf("scan(...): %v", err)
+			}
+			got := tc.cr.Status.AtProvider
+			if !reflect.DeepEqual(got.Unowned, tc.want.unowned) {
+				t.Errorf("scan(...): unowned %+v, want %+v", got.Unowned, tc.want.unowned)
+			}
+			if got.UnownedObjects != int64(len(tc.want.unowned)) || got.OwnedObjects != tc.want.owned {
+				t.Errorf("scan(...): %d unowned and %d owned objects, want %d and %d", got.UnownedObjects, got.OwnedObjects, len(tc.want.unowned), tc.want.owned)
+			}
+			if !reflect.DeepEqual(got.UnresolvedResources, tc.want.unresolved) {
+				t.Errorf("scan(...): unresolved %v, want %v", got.UnresolvedResources, tc.want.unresolved)
+			}
+			if !reflect.DeepEqual(due, tc.want.due) {
+				t.Errorf("scan(...): due %v, want %v", due, tc.want.due)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	seen := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	cases := map[string]struct {
+		objects []string
+		// owner is created after Observe and before Update.
+		owner       client.Object
+		wantDeleted []string
+		wantUnowned []string
+	}{
+		"PrunesDueObjects": {
+			objects:     []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+			wantDeleted: []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+		},
+		"KeepsObjectsThatGotAnOwner": {
+			objects:     []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+			owner:       secret("a", "secret/data/apps/a", ""),
+			wantDeleted: []string{"secret/metadata/apps/b"},
+		},
+		"KeepsObjectsWhileAnOwnerIsUnresolved": {
+			objects:     []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+			owner:       secret("unknown", "", ""),
+			wantUnowned: []string{"secret/metadata/apps/a", "secret/metadata/apps/b"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, vc := newFakeVault(t, tc.objects...)
+			kube := newKube(t)
+			e := &external{kube: kube, client: vc}
+			cr := scope(true, time.Hour, prefix("secret/metadata/apps"))
+			for _, o := range tc.objects {
+				cr.Status.AtProvider.Unowned = append(cr.Status.AtProvider.Unowned, v1alpha1.ManagedScopeObject{Path: o, FirstSeen: seen})
+			}
+
+			obs, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("Observe(...): %v", err)
+			}
+			if obs.ResourceUpToDate {
+				t.Fatalf("Observe(...): up to date with objects due to be deleted")
+			}
+			if tc.owner != nil {
+				if err := kube.Create(context.Background(), tc.owner); err != nil {
+					t.Fatalf("Create(...): %v", err)
+				}
+			}
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("Update(...): %v", err)
+			}
+
+			if !reflect.DeepEqual(v.deleted, tc.wantDeleted) {
+				t.Errorf("Update(...): deleted %v, want %v", v.deleted, tc.wantDeleted)
+			}
+			var unowned []string
+			for _, o := range cr.Status.AtProvider.Unowned {
+				unowned = append(unowned, o.Path)
+			}
+			if !reflect.DeepEqual(unowned, tc.wantUnowned) {
+				t.Errorf("Update(...): unowned %v, want %v", unowned, tc.wantUnowned)
+			}
+			if got := cr.Status.AtProvider.PrunedObjects; got != int64(len(tc.wantDeleted)) {
+				t.Errorf("Update(...): %d pruned objects, want %d", got, len(tc.wantDeleted))
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicationprimary
+
+import (
+	"context"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/replication"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotReplicationPrimary = "managed resource is not a ReplicationPrimary custom resource"
+	errNewClient             = "cannot create new Vault client"
+	errEnable                = "cannot enable replication primary"
+	errDisable               = "cannot disable replication primary"
+	errPrimaryClusterAddr    = "primary cluster address cannot be changed once replication is enabled"
+)
+
+// Setup adds a controller that reconciles ReplicationPrimary managed
+// resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.ReplicationPrimaryGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.ReplicationPrimaryGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.ReplicationPrimaryGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.ReplicationPrimaryGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ReplicationPrimaryGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ReplicationPrimary{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of ReplicationPrimaries that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.ReplicationPrimary); !ok {
+		return nil, errors.New(errNotReplicationPrimary)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{client: vc}, nil
+}
+
+type external struct {
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ReplicationPrimary)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotReplicationPrimary)
+	}
+	st, err := replication.Status(ctx, e.client, cr.Spec.ForProvider.Mode)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if st.Mode != replication.ModePrimary {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	cr.Status.AtProvider = st
+	cr.SetConditions(xpv1.Available())
+	addr := cr.Spec.ForProvider.PrimaryClusterAddr
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: addr == nil || *addr == st.PrimaryClusterAddr,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ReplicationPrimary)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotReplicationPrimary)
+	}
+	body := map[string]interface{}{}
+	if addr := cr.Spec.ForProvider.PrimaryClusterAddr; addr != nil {
+		body["primary_cluster_addr"] = *addr
+	}
+	if _, err := e.client.Write(ctx, replication.Path(cr.Spec.ForProvider.Mode, "primary/enable"), body); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errEnable)
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Mode)
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	// Vault only lets secondaries be pointed at a new primary address, which
+	// is not something a primary can do on its own.
+	return managed.ExternalUpdate{}, errors.New(errPrimaryClusterAddr)
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ReplicationPrimary)
+	if !ok {
+		return errors.New(errNotReplicationPrimary)
+	}
+	_, err := e.client.Write(ctx, replication.Path(cr.Spec.ForProvider.Mode, "primary/disable"), nil)
+	return errors.Wrap(err, errDisable)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicationsecondary
+
+import (
+	"context"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/replication"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotReplicationSecondary = "managed resource is not a ReplicationSecondary custom resource"
+	errNewClient               = "cannot create new Vault client"
+	errActivate                = "cannot activate replication secondary"
+	errDisable                 = "cannot disable replication secondary"
+	errNoToken                 = "neither tokenSecretRef nor secondaryTokenRef is set"
+	errGetSecondaryToken       = "cannot get referenced ReplicationSecondaryToken"
+	errNoConnectionSecret      = "referenced ReplicationSecondaryToken does not write a connection secret"
+	errGetToken                = "cannot get activation token secret"
+	errEmptyToken              = "activation token secret key is empty"
+
+	stateStreamWALs = "stream-wals"
+	connectionReady = "ready"
+)
+
+// Setup adds a controller that reconciles ReplicationSecondary managed
+// resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.ReplicationSecondaryGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.ReplicationSecondaryGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.ReplicationSecondaryGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.ReplicationSecondaryGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ReplicationSecondaryGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ReplicationSecondary{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of ReplicationSecondaries that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.ReplicationSecondary); !ok {
+		return nil, errors.New(errNotReplicationSecondary)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{kube: c.kube, client: vc}, nil
+}
+
+type external struct {
+	kube   client.Client
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ReplicationSecondary)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotReplicationSecondary)
+	}
+	st, err := replication.Status(ctx, e.client, cr.Spec.ForProvider.Mode)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if st.Mode != replication.ModeSecondary {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	cr.Status.AtProvider = st
+	if st.State == stateStreamWALs || st.ConnectionState == connectionReady {
+		cr.SetConditions(xpv1.Available())
+	} else {
+		cr.SetConditions(xpv1.Unavailable().WithMessage("replication state is " + st.State))
+	}
+	// The activation token is single use, so there is nothing to update once
+	// the secondary is activated.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ReplicationSecondary)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotReplicationSecondary)
+	}
+	p := cr.Spec.ForProvider
+	token, err := e.token(ctx, p)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	body := map[string]interface{}{"token": token}
+	if p.PrimaryAPIAddr != nil {
+		body["primary_api_addr"] = *p.PrimaryAPIAddr
+	}
+	if p.CAFile != nil {
+		body["ca_file"] = *p.CAFile
+	}
+	if p.CAPath != nil {
+		body["ca_path"] = *p.CAPath
+	}
+	if _, err := e.client.Write(ctx, replication.Path(p.Mode, "secondary/enable"), body); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errActivate)
+	}
+	meta.SetExternalName(cr, p.Mode)
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ReplicationSecondary)
+	if !ok {
+		return errors.New(errNotReplicationSecondary)
+	}
+	_, err := e.client.Write(ctx, replication.Path(cr.Spec.ForProvider.Mode, "secondary/disable"), nil)
+	return errors.Wrap(err, errDisable)
+}
+
+// token returns the activation token from the referenced secret key or, if
+// there is none, from the connection secret of the referenced
+// ReplicationSecondaryToken.
+func (e *external) token(ctx context.Context, p v1alpha1.ReplicationSecondaryParameters) (string, error) {
+	// The connection secret of a ReplicationSecondaryToken is published by
+	// the provider, and is not necessarily selected by its Secret reader.
+	ref, r := p.TokenSecretRef, clients.SecretReader(e.kube)
+	if ref == nil && p.SecondaryTokenRef != nil {
+		t := &v1alpha1.ReplicationSecondaryToken{}
+		if err := e.kube.Get(ctx, types.NamespacedName{Name: p.SecondaryTokenRef.Name}, t); err != nil {
+			return "", errors.Wrap(err, errGetSecondaryToken)
+		}
+		cs := t.GetWriteConnectionSecretToReference()
+		if cs == nil {
+			return "", errors.New(errNoConnectionSecret)
+		}
+		ref = &xpv1.SecretKeySelector{SecretReference: *cs, Key: v1alpha1.ReplicationSecondaryTokenKey}
+		r = e.kube
+	}
+	if ref == nil {
+		return "", errors.New(errNoToken)
+	}
+	s := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", errors.Wrap(err, errGetToken)
+	}
+	if len(s.Data[ref.Key]) == 0 {
+		return "", errors.New(errEmptyToken)
+	}
+	return string(s.Data[ref.Key]), nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicationsecondarytoken
+
+import (
+	"context"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/replication"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/expiry"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/failure"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+)
+
+const (
+	errNotReplicationSecondaryToken = "managed resource is not a ReplicationSecondaryToken custom resource"
+	errNewClient                    = "cannot create new Vault client"
+	errGenerate                     = "cannot generate secondary activation token"
+	errNoWrapInfo                   = "Vault did not return a wrapped activation token"
+	errRevoke                       = "cannot revoke secondary"
+)
+
+// Setup adds a controller that reconciles ReplicationSecondaryToken managed
+// resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := managed.ControllerName(v1alpha1.ReplicationSecondaryTokenGroupKind)
+	p := poll.New(mgr, resource.ManagedKind(v1alpha1.ReplicationSecondaryTokenGroupVersionKind), o.Options.Options, o.PollLimits)
+	f := failure.New(mgr, resource.ManagedKind(v1alpha1.ReplicationSecondaryTokenGroupVersionKind), name, o.Options.Options)
+	x := expiry.New(mgr, resource.ManagedKind(v1alpha1.ReplicationSecondaryTokenGroupVersionKind), name, o.ExpiryWarning)
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ReplicationSecondaryTokenGroupVersionKind),
+		managed.WithExternalConnecter(p.Connecter(f.Connecter(NewConnector(mgr.GetClient())))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithTimeout(3*time.Minute),
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ReplicationSecondaryToken{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, p.SecretHandler()).
+		Complete(x.Reconciler(p.Reconciler(name, f.Reconciler(r))))
+}
+
+// NewConnector returns an ExternalConnecter of ReplicationSecondaryTokens that reads
+// ProviderConfigs and Secrets through the supplied client.
+func NewConnector(kube client.Client) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: clients.NewVaultClient}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.ReplicationSecondaryToken); !ok {
+		return nil, errors.New(errNotReplicationSecondaryToken)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{client: vc}, nil
+}
+
+type external struct {
+	client *vault.Client
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ReplicationSecondaryToken)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotReplicationSecondaryToken)
+	}
+	st, err := replication.Status(ctx, e.client, cr.Spec.ForProvider.Mode)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if st.Mode != replication.ModePrimary || !contains(st.KnownSecondaries, cr.Spec.ForProvider.ID) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	cr.SetConditions(xpv1.Available())
+	// The activation token is only returned when it is generated, so the
+	// connection secret written by Create is left as it is.
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ReplicationSecondaryToken)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotReplicationSecondaryToken)
+	}
+	body := map[string]interface{}{"id": cr.Spec.ForProvider.ID}
+	if ttl := cr.Spec.ForProvider.TTL; ttl != nil {
+		body["ttl"] = *ttl
+	}
+	s, err := e.client.Write(ctx, replication.Path(cr.Spec.ForProvider.Mode, "primary/secondary-token"), body)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errGenerate)
+	}
+	if s == nil || s.WrapInfo == nil {
+		return managed.ExternalCreation{}, errors.New(errNoWrapInfo)
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.ID)
+	return managed.ExternalCreation{
+		ExternalNameAssigned: true,
+		ConnectionDetails: managed.ConnectionDetails{
+			v1alpha1.ReplicationSecondaryTokenKey: []byte(s.WrapInfo.Token),
+		},
+	}, nil
+}
+
+func (e *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	// An activation token cannot be changed once generated.
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ReplicationSecondaryToken)
+	if !ok {
+		return errors.New(errNotReplicationSecondaryToken)
+	}
+	body := map[string]interface{}{"id": cr.Spec.ForProvider.ID}
+	_, err := e.client.Write(ctx, replication.Path(cr.Spec.ForProvider.Mode, "primary/revoke-secondary"), body)
+	return errors.Wrap(err, errRevoke)
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -19,18 +19,51 @@ package controller
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/crossplane/terrajet/pkg/controller"
-
+	userpassuser "github.com/crossplane-contrib/provider-jet-vault/internal/controller/auth/userpassuser"
 	secret "github.com/crossplane-contrib/provider-jet-vault/internal/controller/generic/secret"
+	oidctoken "github.com/crossplane-contrib/provider-jet-vault/internal/controller/identity/oidctoken"
+	secretcopy "github.com/crossplane-contrib/provider-jet-vault/internal/controller/kv/secretcopy"
+	assignment "github.com/crossplane-contrib/provider-jet-vault/internal/controller/oidc/assignment"
+	client "github.com/crossplane-contrib/provider-jet-vault/internal/controller/oidc/client"
+	provider "github.com/crossplane-contrib/provider-jet-vault/internal/controller/oidc/provider"
+	scope "github.com/crossplane-contrib/provider-jet-vault/internal/controller/oidc/scope"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/options"
 	providerconfig "github.com/crossplane-contrib/provider-jet-vault/internal/controller/providerconfig"
+	association "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sync/association"
+	destination "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sync/destination"
+	auditrequestheader "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/auditrequestheader"
+	corsconfig "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/corsconfig"
+	managedscope "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/managedscope"
+	replicationpathsfilter "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationpathsfilter"
+	replicationprimary "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationprimary"
+	replicationsecondary "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationsecondary"
+	replicationsecondarytoken "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationsecondarytoken"
+	uiheader "github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/uiheader"
 )
 
 // Setup creates all controllers with the supplied logger and adds them to
 // the supplied manager.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	for _, setup := range []func(ctrl.Manager, controller.Options) error{
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	for _, setup := range []func(ctrl.Manager, options.Options) error{
+		userpassuser.Setup,
 		secret.Setup,
+		oidctoken.Setup,
+		secretcopy.Setup,
+		assignment.Setup,
+		client.Setup,
+		provider.Setup,
+		scope.Setup,
 		providerconfig.Setup,
+		association.Setup,
+		destination.Setup,
+		auditrequestheader.Setup,
+		corsconfig.Setup,
+		managedscope.Setup,
+		replicationpathsfilter.Setup,
+		replicationprimary.Setup,
+		replicationsecondary.Setup,
+		replicationsecondarytoken.Setup,
+		uiheader.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
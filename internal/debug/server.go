@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package debug serves the internal state of the provider over HTTP for
+// troubleshooting. Every request has to carry the configured bearer token,
+// which is only sent in plain text on loopback addresses.
+package debug
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/workspace"
+)
+
+const (
+	// PathWorkspaces is the path the Terraform workspaces are listed at.
+	PathWorkspaces = "/debug/workspaces"
+
+	prefixBearer    = "Bearer "
+	shutdownTimeout = 5 * time.Second
+	hostLocal       = "localhost"
+	fileCert        = "tls.crt"
+	fileKey         = "tls.key"
+
+	errNoToken  = "a token is required to serve debug endpoints"
+	errAddr     = "cannot parse debug address"
+	errInsecure = "a TLS certificate is required to serve debug endpoints on a non-loopback address"
+	errListen   = "cannot listen on debug address"
+	errServe    = "cannot serve debug endpoints"
+	errShutdown = "cannot shut down debug server"
+)
+
+// A Server serves the debug endpoints of the provider.
+type Server struct {
+	addr       string
+	certDir    string
+	token      []byte
+	workspaces *workspace.Store
+	log        logging.Logger
+}
+
+// NewServer returns a Server that listens on the supplied address and only
+// answers requests authenticated with the supplied bearer token. It listens on
+// localhost if the address has no host. It serves TLS with the tls.crt and
+// tls.key files of the supplied directory, and refuses to serve plain HTTP on
+// other than loopback addresses.
+func NewServer(addr, token, certDir string, ws *workspace.Store, l logging.Logger) (*Server, error) {
+	if token == "" {
+		return nil, errors.New(errNoToken)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.Wrap(err, errAddr)
+	}
+	if host == "" {
+		host = hostLocal
+	}
+	if certDir == "" && !loopback(host) {
+		return nil, errors.New(errInsecure)
+	}
+	return &Server{addr: net.JoinHostPort(host, port), certDir: certDir, token: []byte(token), workspaces: ws, log: l}, nil
+}
+
+func loopback(host string) bool {
+	if host == hostLocal {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// NeedLeaderElection returns false so that every replica of the provider
+// serves its own workspaces.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the debug endpoints until the supplied context is done.
+func (s *Server) Start(ctx context.Context) error {
+	l, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return errors.Wrap(err, errListen)
+	}
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		done <- errors.Wrap(srv.Shutdown(sctx), errShutdown)
+	}()
+	s.log.Info("Serving debug endpoints", "address", l.Addr().String(), "tls", s.certDir != "")
+	serve := func() error { return srv.Serve(l) }
+	if s.certDir != "" {
+		serve = func() error {
+			return srv.ServeTLS(l, filepath.Join(s.certDir, fileCert), filepath.Join(s.certDir, fileKey))
+		}
+	}
+	if err := serve(); err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, errServe)
+	}
+	return <-done
+}
+
+// ServeHTTP authenticates the request and serves the requested endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authenticated(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="provider-jet-vault"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	switch r.URL.Path {
+	case PathWorkspaces:
+		s.write(w, struct {
+			Workspaces []workspace.Status `json:"workspaces"`
+		}{Workspaces: s.workspaces.List()})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefixBearer) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(h, prefixBearer)), s.token) == 1
+}
+
+func (s *Server) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		s.log.Debug("Cannot write debug response", "error", err)
+	}
+}
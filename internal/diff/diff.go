@@ -0,0 +1,410 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff compares managed resources with the current state of Vault
+// without a Kubernetes cluster, e.g. to review the changes a set of manifests
+// would cause before they are applied.
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	tjconfig "github.com/crossplane/terrajet/pkg/config"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/terraform"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	authv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/auth/v1alpha1"
+	identityv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/identity/v1alpha1"
+	kvv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/kv/v1alpha1"
+	syncv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/sync/v1alpha1"
+	sysv1alpha1 "github.com/crossplane-contrib/provider-jet-vault/apis/sys/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/auth/userpassuser"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/identity/oidctoken"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/kv/secretcopy"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sync/association"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sync/destination"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/auditrequestheader"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/corsconfig"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/managedscope"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationpathsfilter"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationprimary"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationsecondary"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/replicationsecondarytoken"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/controller/sys/uiheader"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/openapi"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/render"
+)
+
+const (
+	prefixForProvider = "spec.forProvider."
+
+	errNoConfig       = "no configuration for Terraform resource"
+	errSetup          = "cannot build Terraform setup"
+	errWorkspace      = "cannot create Terraform workspace"
+	errRefresh        = "cannot refresh Terraform state"
+	errGetParameters  = "cannot get parameters"
+	errGetSensitive   = "cannot get sensitive parameters"
+	errGetAttributes  = "cannot get Terraform state attributes"
+	errNewVaultClient = "cannot create Vault client"
+	errRead           = "cannot read Vault object"
+	errConnect        = "cannot connect to Vault"
+	errObserve        = "cannot observe Vault object"
+)
+
+// identifiers are the parameters that hold the Terraform IDs of the
+// Terraform resources whose IDs are not known before they are created.
+var identifiers = map[string]string{
+	"vault_generic_secret": "path",
+}
+
+// connectors return the ExternalConnecters of the hand-written kinds, keyed
+// by group kind. Their controllers compare them with Vault.
+var connectors = map[string]func(kube client.Client) managed.ExternalConnecter{
+	authv1alpha1.UserpassUserGroupKind:             userpassuser.NewConnector,
+	identityv1alpha1.OIDCTokenGroupKind:            oidctoken.NewConnector,
+	kvv1alpha1.SecretCopyGroupKind:                 secretcopy.NewConnector,
+	syncv1alpha1.AssociationGroupKind:              association.NewConnector,
+	syncv1alpha1.DestinationGroupKind:              destination.NewConnector,
+	sysv1alpha1.AuditRequestHeaderGroupKind:        auditrequestheader.NewConnector,
+	sysv1alpha1.CORSConfigGroupKind:                corsconfig.NewConnector,
+	sysv1alpha1.ManagedScopeGroupKind:              managedscope.NewConnector,
+	sysv1alpha1.ReplicationPathsFilterGroupKind:    replicationpathsfilter.NewConnector,
+	sysv1alpha1.ReplicationPrimaryGroupKind:        replicationprimary.NewConnector,
+	sysv1alpha1.ReplicationSecondaryGroupKind:      replicationsecondary.NewConnector,
+	sysv1alpha1.ReplicationSecondaryTokenGroupKind: replicationsecondarytoken.NewConnector,
+	sysv1alpha1.UIHeaderGroupKind:                  uiheader.NewConnector,
+}
+
+// A Field is a field of a managed resource whose desired value differs from
+// its current one. Values are JSON encoded, or masked if they are sensitive.
+type Field struct {
+	Path    string
+	Desired string
+	Current string
+}
+
+// A Resource is the difference between a managed resource and the object it
+// represents in Vault.
+type Resource struct {
+	Kind string
+	Name string
+
+	// Exists is false if the object does not exist in Vault, in which case
+	// Fields contains every desired field.
+	Exists bool
+
+	// Unsupported is true if the kind of the managed resource cannot be
+	// compared offline.
+	Unsupported bool
+
+	// Drifted is true if the object differs from the managed resource in
+	// fields that are not known, which is the case for the hand-written
+	// kinds that are compared by their controllers.
+	Drifted bool
+
+	Fields []Field
+}
+
+// HasChanges returns true if applying the managed resource would change
+// Vault.
+func (r Resource) HasChanges() bool {
+	return !r.Unsupported && (!r.Exists || r.Drifted || len(r.Fields) > 0)
+}
+
+// A Differ compares managed resources with Vault. Resources that are
+// reconciled with Terraform are refreshed in a Terraform workspace, the ones
+// generated from the Vault OpenAPI document are read through the Vault API
+// and the hand-written ones are observed by their controllers.
+type Differ struct {
+	kube     client.Client
+	provider *tjconfig.Provider
+	setupFn  terraform.SetupFn
+	store    *terraform.WorkspaceStore
+}
+
+// NewDiffer returns a Differ that resolves ProviderConfigs and Secrets
+// through the supplied client.
+func NewDiffer(kube client.Client, pc *tjconfig.Provider, setupFn terraform.SetupFn, store *terraform.WorkspaceStore) *Differ {
+	return &Differ{kube: kube, provider: pc, setupFn: setupFn, store: store}
+}
+
+// Diff returns the difference between the supplied managed resource and
+// Vault.
+func (d *Differ) Diff(ctx context.Context, mg xpresource.Managed) (Resource, error) {
+	r := Resource{Kind: mg.GetObjectKind().GroupVersionKind().Kind, Name: mg.GetName()}
+	// Workspaces and ProviderConfig usages are keyed by UID, which local
+	// manifests usually lack.
+	mg = mg.DeepCopyObject().(xpresource.Managed)
+	if mg.GetUID() == "" {
+		mg.SetUID(uuid.NewUUID())
+	}
+	switch o := mg.(type) {
+	case resource.Terraformed:
+		return d.diffTerraformed(ctx, r, o)
+	case openapi.Object:
+		return d.diffOpenAPI(ctx, r, o)
+	}
+	if c, ok := connectors[mg.GetObjectKind().GroupVersionKind().GroupKind().String()]; ok {
+		return d.diffNative(ctx, r, mg, c(d.kube))
+	}
+	r.Unsupported = true
+	return r, nil
+}
+
+func (d *Differ) diffTerraformed(ctx context.Context, r Resource, tr resource.Terraformed) (Resource, error) {
+	cfg, ok := d.provider.Resources[tr.GetTerraformResourceType()]
+	if !ok {
+		return r, errors.Errorf("%s %s", errNoConfig, tr.GetTerraformResourceType())
+	}
+	sc := render.NewSecretClient(d.kube)
+	desired, err := tr.GetParameters()
+	if err != nil {
+		return r, errors.Wrap(err, errGetParameters)
+	}
+	mapping := tr.GetConnectionDetailsMapping()
+	if err := resource.GetSensitiveParameters(ctx, sc, tr, desired, mapping); err != nil {
+		return r, errors.Wrap(err, errGetSensitive)
+	}
+	sensitive := sensitivePaths(mapping)
+
+	// Without an external name the Terraform ID is derived from the
+	// parameter that holds it, if any. Otherwise there is nothing to
+	// refresh, the resource would be created.
+	if meta.GetExternalName(tr) == "" {
+		id, _ := desired[identifiers[tr.GetTerraformResourceType()]].(string)
+		if id == "" {
+			r.Fields = compare(desired, nil, sensitive)
+			return r, nil
+		}
+		meta.SetExternalName(tr, id)
+	}
+	ts, err := d.setupFn(ctx, d.kube, tr)
+	if err != nil {
+		return r, errors.Wrap(err, errSetup)
+	}
+	ws, err := d.store.Workspace(ctx, sc, tr, ts, cfg)
+	if err != nil {
+		return r, errors.Wrap(err, errWorkspace)
+	}
+	defer d.store.Remove(tr) // nolint:errcheck
+	res, err := ws.Refresh(ctx)
+	if err != nil {
+		return r, errors.Wrap(err, errRefresh)
+	}
+	current := map[string]interface{}{}
+	if res.Exists {
+		if err := json.Unmarshal(res.State.GetAttributes(), &current); err != nil {
+			return r, errors.Wrap(err, errGetAttributes)
+		}
+	}
+	r.Exists = res.Exists
+	r.Fields = compare(desired, current, sensitive)
+	return r, nil
+}
+
+func (d *Differ) diffOpenAPI(ctx context.Context, r Resource, o openapi.Object) (Resource, error) {
+	desired, err := o.GetParameters()
+	if err != nil {
+		return r, errors.Wrap(err, errGetParameters)
+	}
+	vc, err := clients.NewVaultClient(ctx, d.kube, o)
+	if err != nil {
+		return r, errors.Wrap(err, errNewVaultClient)
+	}
+	s, err := vc.Read(ctx, o.GetVaultPath())
+	if xpresource.Ignore(vault.IsNotFound, err) != nil {
+		return r, errors.Wrap(err, errRead)
+	}
+	if s == nil {
+		r.Fields = compare(desired, nil, nil)
+		return r, nil
+	}
+	r.Exists = true
+	// Sensitive parameters are never returned by Vault, so they are not
+	// part of the comparison.
+	for _, k := range openapi.ChangedParameters(desired, s.Data) {
+		r.Fields = append(r.Fields, Field{Path: k, Desired: encode(desired[k]), Current: encode(s.Data[k])})
+	}
+	return r, nil
+}
+
+func (d *Differ) diffNative(ctx context.Context, r Resource, mg xpresource.Managed, c managed.ExternalConnecter) (Resource, error) {
+	// Like the managed reconciler, use the name of the resource as its
+	// external name unless it has one.
+	if meta.GetExternalName(mg) == "" {
+		meta.SetExternalName(mg, mg.GetName())
+	}
+	ec, err := c.Connect(ctx, mg)
+	if err != nil {
+		return r, errors.Wrap(err, errConnect)
+	}
+	o, err := ec.Observe(ctx, mg)
+	if err != nil {
+		return r, errors.Wrap(err, errObserve)
+	}
+	r.Exists = o.ResourceExists
+	r.Drifted = o.ResourceExists && !o.ResourceUpToDate
+	return r, nil
+}
+
+// sensitivePaths returns the Terraform paths of the parameters that are read
+// from Secrets according to the supplied connection details mapping.
+func sensitivePaths(mapping map[string]string) []string {
+	var paths []string
+	for tf, xp := range mapping {
+		if strings.HasPrefix(xp, prefixForProvider) {
+			paths = append(paths, tf)
+		}
+	}
+	return paths
+}
+
+// compare returns the fields of desired whose values differ from current.
+// Fields that are not desired, e.g. computed ones, are not compared.
+func compare(desired, current map[string]interface{}, sensitive []string) []Field {
+	want := flatten("", desired, map[string]interface{}{})
+	got := flatten("", current, map[string]interface{}{})
+	var fields []Field
+	for _, p := range sortedKeys(want) {
+		w, g := want[p], got[p]
+		if equal(w, g) {
+			continue
+		}
+		f := Field{Path: p, Desired: encode(w), Current: encode(g)}
+		if isSensitive(p, sensitive) {
+			f.Desired, f.Current = render.Masked, render.Masked
+			if g == nil {
+				f.Current = encode(nil)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// flatten adds the leaf values of the supplied value to the supplied map,
+// keyed by their field paths.
+func flatten(path string, v interface{}, into map[string]interface{}) map[string]interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			flatten(p, e, into)
+		}
+	case []interface{}:
+		for i, e := range t {
+			flatten(fmt.Sprintf("%s[%d]", path, i), e, into)
+		}
+	default:
+		if path != "" {
+			into[path] = v
+		}
+	}
+	return into
+}
+
+// equal compares two leaf values. Strings holding JSON documents, such as
+// data_json, are compared by content since Vault does not preserve their
+// formatting.
+func equal(a, b interface{}) bool {
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			var aj, bj interface{}
+			if json.Unmarshal([]byte(as), &aj) == nil && json.Unmarshal([]byte(bs), &bj) == nil {
+				return reflect.DeepEqual(aj, bj)
+			}
+		}
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// normalize returns the supplied value as it is decoded from JSON, so that
+// e.g. integers compare equal to the floats of Terraform state.
+func normalize(v interface{}) interface{} {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var n interface{}
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return v
+	}
+	return n
+}
+
+// isSensitive returns true if the supplied field path matches one of the
+// supplied Terraform paths, which may contain wildcards.
+func isSensitive(path string, sensitive []string) bool {
+	got, err := fieldpath.Parse(path)
+	if err != nil {
+		return false
+	}
+	for _, s := range sensitive {
+		want, err := fieldpath.Parse(s)
+		if err != nil || len(want) > len(got) {
+			continue
+		}
+		match := true
+		for i := range want {
+			if want[i].Field == "*" || (want[i].Type == got[i].Type && want[i].Field == got[i].Field) {
+				continue
+			}
+			match = false
+			break
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func encode(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package expiry deletes managed resources, and with them their Vault
+// objects, once the expiry time set in their annotations passes, e.g. for the
+// secrets of preview environments.
+package expiry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// AnnotationExpiresAt is the RFC 3339 time a managed resource expires at.
+	AnnotationExpiresAt = "vault.jet.crossplane.io/expires-at"
+	// AnnotationTTL is the duration, e.g. 72h, a managed resource expires
+	// after, counted from its creation.
+	AnnotationTTL = "vault.jet.crossplane.io/ttl"
+
+	reasonExpiresSoon   event.Reason = "ExpiresSoon"
+	reasonExpired       event.Reason = "Expired"
+	reasonInvalidExpiry event.Reason = "InvalidExpiry"
+	reasonCannotDelete  event.Reason = "CannotDeleteExpired"
+
+	errParseExpiresAt = "cannot parse " + AnnotationExpiresAt + " annotation"
+	errParseTTL       = "cannot parse " + AnnotationTTL + " annotation"
+	errDelete         = "cannot delete expired managed resource"
+
+	fmtExpiresSoon = "Managed resource and its Vault object will be deleted at %s, update the %s or %s annotation to extend it"
+	fmtExpired     = "Managed resource expired at %s and is deleted with its Vault object"
+)
+
+// ExpiresAt returns the time the supplied managed resource expires at, the
+// earlier one if both annotations are set, and false if it does not expire.
+func ExpiresAt(o metav1.Object) (time.Time, bool, error) {
+	var at time.Time
+	if v, ok := o.GetAnnotations()[AnnotationExpiresAt]; ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, false, errors.Wrap(err, errParseExpiresAt)
+		}
+		at = t
+	}
+	if v, ok := o.GetAnnotations()[AnnotationTTL]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return time.Time{}, false, errors.Wrap(err, errParseTTL)
+		}
+		if t := o.GetCreationTimestamp().Add(d); at.IsZero() || t.Before(at) {
+			at = t
+		}
+	}
+	return at, !at.IsZero(), nil
+}
+
+// An Expirer deletes the expired managed resources of one controller.
+type Expirer struct {
+	kube       client.Client
+	newManaged func() resource.Managed
+	record     event.Recorder
+	warning    time.Duration
+
+	mu     sync.Mutex
+	warned map[types.NamespacedName]time.Time
+}
+
+// New returns an Expirer of the managed resources of the supplied kind that
+// warns about them the supplied duration before they expire.
+func New(mgr ctrl.Manager, of resource.ManagedKind, name string, warning time.Duration) *Expirer {
+	return &Expirer{
+		kube: mgr.GetClient(),
+		newManaged: func() resource.Managed {
+			return resource.MustCreateObject(schema.GroupVersionKind(of), mgr.GetScheme()).(resource.Managed)
+		},
+		record:  event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		warning: warning,
+		warned:  map[types.NamespacedName]time.Time{},
+	}
+}
+
+// Reconciler returns a Reconciler that deletes expired managed resources,
+// warns about the ones that expire soon and makes sure the supplied
+// Reconciler requeues resources by the time they have to be warned about or
+// deleted. The managed reconciler then deletes their Vault objects according
+// to their deletion policy.
+func (x *Expirer) Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		mg := x.newManaged()
+		if err := x.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+			x.forget(req.NamespacedName)
+			return r.Reconcile(ctx, req)
+		}
+		at, ok, err := ExpiresAt(mg)
+		if err != nil {
+			x.record.Event(mg, event.Warning(reasonInvalidExpiry, err))
+		}
+		if !ok || meta.WasDeleted(mg) {
+			x.forget(req.NamespacedName)
+			return r.Reconcile(ctx, req)
+		}
+
+		now := time.Now()
+		if !now.Before(at) {
+			x.record.Event(mg, event.Warning(reasonExpired, errors.Errorf(fmtExpired, at.Format(time.RFC3339))))
+			if err := x.kube.Delete(ctx, mg); client.IgnoreNotFound(err) != nil {
+				x.record.Event(mg, event.Warning(reasonCannotDelete, errors.Wrap(err, errDelete)))
+				return reconcile.Result{}, errors.Wrap(err, errDelete)
+			}
+			// Deleting the resource enqueues it for the managed reconciler.
+			return reconcile.Result{}, nil
+		}
+		warnAt := at.Add(-x.warning)
+		if !now.Before(warnAt) && x.warn(req.NamespacedName, at) {
+			x.record.Event(mg, event.Warning(reasonExpiresSoon, errors.Errorf(fmtExpiresSoon, at.Format(time.RFC3339), AnnotationExpiresAt, AnnotationTTL)))
+		}
+
+		res, err := r.Reconcile(ctx, req)
+		if err != nil || (res.Requeue && res.RequeueAfter == 0) {
+			return res, err
+		}
+		next := at.Sub(now)
+		if now.Before(warnAt) {
+			next = warnAt.Sub(now)
+		}
+		// Requeue a moment after the deadline rather than right before it.
+		next += time.Second
+		if res.RequeueAfter == 0 || next < res.RequeueAfter {
+			res.RequeueAfter = next
+		}
+		return res, nil
+	})
+}
+
+// warn returns true if the supplied resource has not been warned about
+// expiring at the supplied time yet.
+func (x *Expirer) warn(nn types.NamespacedName, at time.Time) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if t, ok := x.warned[nn]; ok && t.Equal(at) {
+		return false
+	}
+	x.warned[nn] = at
+	return true
+}
+
+func (x *Expirer) forget(nn types.NamespacedName) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.warned, nn)
+}
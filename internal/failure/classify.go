@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package failure classifies the errors of Terraform and of the Vault API
+// into actionable categories, reports them in the conditions and events of
+// managed resources, and retries the transient ones with backoff while
+// failing fast on the permanent ones.
+package failure
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/terrajet/pkg/types/name"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+// Reasons of the Synced condition of managed resources that failed to
+// reconcile with a classified error.
+const (
+	ReasonPermissionDenied   xpv1.ConditionReason = "PermissionDenied"
+	ReasonInvalidInput       xpv1.ConditionReason = "InvalidInput"
+	ReasonNotFound           xpv1.ConditionReason = "NotFound"
+	ReasonRateLimited        xpv1.ConditionReason = "RateLimited"
+	ReasonUnavailable        xpv1.ConditionReason = "VaultUnavailable"
+	ReasonTLSFailure         xpv1.ConditionReason = "TLSFailure"
+	ReasonCredentialsExpired xpv1.ConditionReason = "CredentialsExpired"
+)
+
+const (
+	pathForProvider = "spec.forProvider."
+)
+
+var (
+	// reCode matches the status code in the errors of the Vault API client of
+	// the Terraform provider, e.g. "Code: 403. Errors:", and of the one of
+	// this provider, e.g. "Vault responded with code 403".
+	reCode = regexp.MustCompile(`(?:Code:|responded with code) (\d{3})\b`)
+	// reAttribute matches the attribute Terraform diagnostics and Vault
+	// errors refer to, e.g. `The argument "path" is required` or
+	// `error converting input for field "ttl"`.
+	reAttribute = regexp.MustCompile(`(?:argument|attribute|field|key) (?:named )?["']([A-Za-z0-9_]+)["']`)
+
+	credentialsExpired = []string{"token expired", "token is expired", "invalid token", "bad token", "token not found", "lease expired"}
+	tlsFailure         = []string{"x509: ", "tls: ", "remote error: tls"}
+	rateLimited        = []string{"rate limit", "too many requests"}
+	unavailable        = []string{"vault is sealed", "standby", "connection refused"}
+	permissionDenied   = []string{"permission denied"}
+	notFound           = []string{"no handler for route", "cannot import non-existent remote object", " not found"}
+	invalidInput       = []string{"missing required argument", "unsupported argument", "invalid value", "incorrect attribute value type", "invalid or unknown key", "unsupported block type", "field validation failed"}
+)
+
+// A Failure is a classified error.
+type Failure struct {
+	// Reason is the category of the error.
+	Reason xpv1.ConditionReason
+	// Attribute is the path of the managed resource field the error refers
+	// to, if any, e.g. spec.forProvider.dataJson.
+	Attribute string
+	// Transient is true if retrying the operation may succeed without the
+	// managed resource or its ProviderConfig being changed.
+	Transient bool
+}
+
+// Classify returns the Failure of the supplied error, and false if it does
+// not belong to a known category.
+func Classify(err error) (Failure, bool) {
+	if err == nil {
+		return Failure{}, false
+	}
+	msg := err.Error()
+	lower := strings.ToLower(msg)
+	code := 0
+	re := &vault.ResponseError{}
+	if errors.As(err, &re) {
+		code = re.StatusCode
+	} else if m := reCode.FindStringSubmatch(msg); m != nil {
+		code, _ = strconv.Atoi(m[1])
+	}
+
+	f := Failure{}
+	switch {
+	case containsAny(lower, credentialsExpired):
+		f.Reason = ReasonCredentialsExpired
+	case containsAny(lower, tlsFailure):
+		f.Reason = ReasonTLSFailure
+	case code == 429 || containsAny(lower, rateLimited):
+		f.Reason, f.Transient = ReasonRateLimited, true
+	case code == 503 || containsAny(lower, unavailable):
+		f.Reason, f.Transient = ReasonUnavailable, true
+	case code == 403 || containsAny(lower, permissionDenied):
+		f.Reason = ReasonPermissionDenied
+	case code == 404 || containsAny(lower, notFound):
+		f.Reason = ReasonNotFound
+	case code == 400 || containsAny(lower, invalidInput):
+		f.Reason = ReasonInvalidInput
+	default:
+		return Failure{}, false
+	}
+	if m := reAttribute.FindStringSubmatch(msg); m != nil {
+		f.Attribute = pathForProvider + name.NewFromSnake(m[1]).LowerCamelComputed
+	}
+	return f, true
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
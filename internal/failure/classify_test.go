@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failure
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+// The Terraform errors are wrapped the way Terrajet wraps the output of a
+// failed terraform apply.
+const (
+	tfPermissionDenied = `cannot apply: apply failed: error writing to Vault: Error making API request.
+
+URL: PUT https://vault.example.com:8200/v1/sys/policy/team-a
+Code: 403. Errors:
+
+* 1 error occurred:
+	* permission denied
+
+: File name: main.tf.json`
+
+	tfTokenExpired = `cannot apply: apply failed: failed to lookup token, err=Error making API request.
+
+URL: GET https://vault.example.com:8200/v1/auth/token/lookup-self
+Code: 403. Errors:
+
+* 2 errors occurred:
+	* permission denied
+	* invalid token
+
+: File name: main.tf.json`
+
+	tfInvalidField = `cannot apply: apply failed: error writing Kubernetes backend role "auth/kubernetes/role/app": Error making API request.
+
+URL: PUT https://vault.example.com:8200/v1/auth/kubernetes/role/app
+Code: 400. Errors:
+
+* error converting input 1x for field "token_ttl": time: unknown unit "x" in duration "1x": File name: main.tf.json`
+
+	tfMissingArgument = `cannot plan: plan failed: Missing required argument: The argument "path" is required, but no definition was found.: File name: main.tf.json`
+
+	tfNotFound = `cannot refresh: refresh failed: error reading from Vault: Error making API request.
+
+URL: GET https://vault.example.com:8200/v1/identity/entity/id/1c6d0ae4
+Code: 404. Errors:
+
+: File name: main.tf.json`
+
+	tfNoHandler = `cannot apply: apply failed: error writing to Vault: Error making API request.
+
+URL: PUT https://vault.example.com:8200/v1/oidc/key/default
+Code: 405. Errors:
+
+* 1 error occurred:
+	* no handler for route "oidc/key/default". route entry not found.
+
+: File name: main.tf.json`
+
+	tfRateLimited = `cannot apply: apply failed: error writing to Vault: Error making API request.
+
+URL: PUT https://vault.example.com:8200/v1/secret/data/app
+Code: 429. Errors:
+
+* 1 error occurred:
+	* request path "secret/data/app": rate limit quota exceeded
+
+: File name: main.tf.json`
+
+	tfSealed = `cannot refresh: refresh failed: error reading from Vault: Error making API request.
+
+URL: GET https://vault.example.com:8200/v1/sys/policy/team-a
+Code: 503. Errors:
+
+* Vault is sealed: File name: main.tf.json`
+
+	tfConnectionRefused = `cannot refresh: refresh failed: failed to lookup token, err=Get "https://vault.example.com:8200/v1/auth/token/lookup-self": dial tcp 10.96.0.12:8200: connect: connection refused: File name: main.tf.json`
+
+	tfUnknownAuthority = `cannot refresh: refresh failed: failed to lookup token, err=Get "https://vault.example.com:8200/v1/auth/token/lookup-self": x509: certificate signed by unknown authority: File name: main.tf.json`
+)
+
+func TestClassify(t *testing.T) {
+	cases := map[string]struct {
+		err    error
+		want   Failure
+		wantOK bool
+	}{
+		"Nil": {
+			err: nil,
+		},
+		"Unknown": {
+			err: errors.New("cannot apply: apply failed: exit status 1: File name: main.tf.json"),
+		},
+		"TerraformPermissionDenied": {
+			err:    errors.New(tfPermissionDenied),
+			want:   Failure{Reason: ReasonPermissionDenied},
+			wantOK: true,
+		},
+		"TerraformTokenExpired": {
+			err:    errors.New(tfTokenExpired),
+			want:   Failure{Reason: ReasonCredentialsExpired},
+			wantOK: true,
+		},
+		"TerraformInvalidField": {
+			err:    errors.New(tfInvalidField),
+			want:   Failure{Reason: ReasonInvalidInput, Attribute: "spec.forProvider.tokenTtl"},
+			wantOK: true,
+		},
+		"TerraformMissingArgument": {
+			err:    errors.New(tfMissingArgument),
+			want:   Failure{Reason: ReasonInvalidInput, Attribute: "spec.forProvider.path"},
+			wantOK: true,
+		},
+		"TerraformNotFound": {
+			err:    errors.New(tfNotFound),
+			want:   Failure{Reason: ReasonNotFound},
+			wantOK: true,
+		},
+		"TerraformNoHandler": {
+			err:    errors.New(tfNoHandler),
+			want:   Failure{Reason: ReasonNotFound},
+			wantOK: true,
+		},
+		"TerraformRateLimited": {
+			err:    errors.New(tfRateLimited),
+			want:   Failure{Reason: ReasonRateLimited, Transient: true},
+			wantOK: true,
+		},
+		"TerraformSealed": {
+			err:    errors.New(tfSealed),
+			want:   Failure{Reason: ReasonUnavailable, Transient: true},
+			wantOK: true,
+		},
+		"TerraformConnectionRefused": {
+			err:    errors.New(tfConnectionRefused),
+			want:   Failure{Reason: ReasonUnavailable, Transient: true},
+			wantOK: true,
+		},
+		"TerraformUnknownAuthority": {
+			err:    errors.New(tfUnknownAuthority),
+			want:   Failure{Reason: ReasonTLSFailure},
+			wantOK: true,
+		},
+		"VaultPermissionDenied": {
+			err:    errors.Wrap(&vault.ResponseError{Method: "POST", Path: "sys/config/cors", StatusCode: 403, Errors: []string{"1 error occurred:\n\t* permission denied\n\n"}}, "cannot write CORS config"),
+			want:   Failure{Reason: ReasonPermissionDenied},
+			wantOK: true,
+		},
+		"VaultTokenExpired": {
+			err:    errors.Wrap(&vault.ResponseError{Method: "GET", Path: "sys/config/cors", StatusCode: 403, Errors: []string{"2 errors occurred:\n\t* permission denied\n\t* token is expired\n\n"}}, "cannot read CORS config"),
+			want:   Failure{Reason: ReasonCredentialsExpired},
+			wantOK: true,
+		},
+		"VaultInvalidField": {
+			err:    errors.Wrap(&vault.ResponseError{Method: "POST", Path: "sys/replication/performance/primary/secondary-token", StatusCode: 400, Errors: []string{`error converting input 1x for field "ttl": time: unknown unit "x" in duration "1x"`}}, "cannot create secondary activation token"),
+			want:   Failure{Reason: ReasonInvalidInput, Attribute: "spec.forProvider.ttl"},
+			wantOK: true,
+		},
+		"VaultNotFound": {
+			err:    errors.Wrap(&vault.ResponseError{Method: "GET", Path: "sys/replication/performance/primary/paths-filter/abc", StatusCode: 404}, "cannot read paths filter"),
+			want:   Failure{Reason: ReasonNotFound},
+			wantOK: true,
+		},
+		"VaultRateLimited": {
+			err:    errors.Wrap(&vault.ResponseError{Method: "GET", Path: "secret/data/app", StatusCode: 429, Errors: []string{`request path "secret/data/app": rate limit quota exceeded`}}, "cannot read source secret"),
+			want:   Failure{Reason: ReasonRateLimited, Transient: true},
+			wantOK: true,
+		},
+		"VaultSealed": {
+			err:    errors.Wrap(&vault.ResponseError{Method: "POST", Path: "sys/policy/team-a", StatusCode: 503, Errors: []string{"Vault is sealed"}}, "cannot write policy"),
+			want:   Failure{Reason: ReasonUnavailable, Transient: true},
+			wantOK: true,
+		},
+		"VaultTLSHandshake": {
+			err:    errors.New(`cannot send request to Vault: Get "https://vault.example.com:8200/v1/sys/config/cors": remote error: tls: bad certificate`),
+			want:   Failure{Reason: ReasonTLSFailure},
+			wantOK: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := Classify(tc.err)
+			if ok != tc.wantOK {
+				t.Fatalf("Classify(...): ok %t, want %t", ok, tc.wantOK)
+			}
+			if got != tc.want {
+				t.Errorf("Classify(...): %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
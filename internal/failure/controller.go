@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package failure
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	annotationAttribute = "attribute"
+
+	backoffMin = time.Second
+	backoffMax = 5 * time.Minute
+	// rateLimitedMin is the least time a rate limited resource waits before
+	// it is retried.
+	rateLimitedMin = 30 * time.Second
+)
+
+// A Handler reports the classified errors of the managed resources of one
+// controller and decides when they are retried.
+type Handler struct {
+	kube       client.Client
+	newManaged func() resource.Managed
+	record     event.Recorder
+	poll       time.Duration
+	backoff    workqueue.RateLimiter
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]Failure
+}
+
+// New returns a Handler of the managed resources of the supplied kind.
+func New(mgr ctrl.Manager, of resource.ManagedKind, name string, o xpcontroller.Options) *Handler {
+	return &Handler{
+		kube: mgr.GetClient(),
+		newManaged: func() resource.Managed {
+			return resource.MustCreateObject(schema.GroupVersionKind(of), mgr.GetScheme()).(resource.Managed)
+		},
+		record:   event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		poll:     o.PollInterval,
+		backoff:  workqueue.NewItemExponentialFailureRateLimiter(backoffMin, backoffMax),
+		failures: map[types.NamespacedName]Failure{},
+	}
+}
+
+// Connecter returns an ExternalConnecter that classifies the errors of the
+// supplied ExternalConnecter and of the ExternalClients it connects.
+func (h *Handler) Connecter(c managed.ExternalConnecter) managed.ExternalConnecter {
+	return managed.ExternalConnectorFn(func(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+		ec, err := c.Connect(ctx, mg)
+		if err != nil {
+			h.classify(mg, err)
+			return nil, err
+		}
+		return &external{ExternalClient: ec, handler: h}, nil
+	})
+}
+
+// Reconciler returns a Reconciler that sets the reason of the Synced condition
+// of the resources the supplied Reconciler failed to reconcile with a
+// classified error, and emits an event of the same reason. Transient errors
+// are retried with exponential backoff, permanent ones only at the poll
+// interval or when the resource changes.
+func (h *Handler) Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		h.forget(req.NamespacedName)
+		res, err := r.Reconcile(ctx, req)
+		f, ok := h.failure(req.NamespacedName)
+		if !ok || err != nil {
+			if err == nil && !res.Requeue {
+				h.backoff.Forget(req)
+			}
+			return res, err
+		}
+		if err := h.report(ctx, req.NamespacedName, f); err != nil {
+			return reconcile.Result{}, err
+		}
+		if !f.Transient {
+			h.backoff.Forget(req)
+			return reconcile.Result{RequeueAfter: h.poll}, nil
+		}
+		wait := h.backoff.When(req)
+		if f.Reason == ReasonRateLimited && wait < rateLimitedMin {
+			wait = rateLimitedMin
+		}
+		return reconcile.Result{RequeueAfter: wait}, nil
+	})
+}
+
+// report sets the reason of the Synced condition of the supplied resource to
+// the one of its Failure, keeping the message the managed reconciler set.
+func (h *Handler) report(ctx context.Context, nn types.NamespacedName, f Failure) error {
+	mg := h.newManaged()
+	if err := h.kube.Get(ctx, nn, mg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if meta.WasDeleted(mg) && len(mg.GetFinalizers()) == 0 {
+		return nil
+	}
+	c := mg.GetCondition(xpv1.TypeSynced)
+	if c.Status != corev1.ConditionFalse {
+		return nil
+	}
+	var kv []string
+	if f.Attribute != "" {
+		kv = []string{annotationAttribute, f.Attribute}
+	}
+	h.record.Event(mg, event.Warning(event.Reason(f.Reason), errors.New(c.Message), kv...))
+	if c.Reason == f.Reason {
+		return nil
+	}
+	c.Reason = f.Reason
+	c.LastTransitionTime = metav1.Now()
+	mg.SetConditions(c)
+	return client.IgnoreNotFound(h.kube.Status().Update(ctx, mg))
+}
+
+func (h *Handler) classify(mg resource.Managed, err error) {
+	f, ok := Classify(err)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[types.NamespacedName{Namespace: mg.GetNamespace(), Name: mg.GetName()}] = f
+}
+
+func (h *Handler) failure(nn types.NamespacedName) (Failure, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, ok := h.failures[nn]
+	return f, ok
+}
+
+func (h *Handler) forget(nn types.NamespacedName) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.failures, nn)
+}
+
+// external classifies the errors of the operations of an ExternalClient.
+type external struct {
+	managed.ExternalClient
+	handler *Handler
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	if err != nil {
+		e.handler.classify(mg, err)
+	}
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.ExternalClient.Create(ctx, mg)
+	if err != nil {
+		e.handler.classify(mg, err)
+	}
+	return c, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.ExternalClient.Update(ctx, mg)
+	if err != nil {
+		e.handler.classify(mg, err)
+	}
+	return u, err
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.ExternalClient.Delete(ctx, mg)
+	if err != nil {
+		e.handler.classify(mg, err)
+	}
+	return err
+}
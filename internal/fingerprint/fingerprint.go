@@ -0,0 +1,302 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fingerprint skips the Terraform runs of polls that would not find
+// anything to do. After Terraform found a managed resource up to date, a
+// fingerprint of its desired parameters, including the contents of the
+// Secrets they reference, and a fingerprint of its state read natively from
+// Vault are stored. As long as neither of them changes, later polls report
+// the resource as up to date without running Terraform.
+package fingerprint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+const (
+	prefixSpec = "spec.forProvider."
+
+	errNotTerraformed = "managed resource is not a Terraformed resource"
+	errGetParameters  = "cannot get parameters"
+	errPave           = "cannot pave managed resource"
+	errGetSecretRef   = "cannot get secret reference"
+	errGetSecret      = "cannot get sensitive parameter secret"
+	errMarshal        = "cannot marshal fingerprinted state"
+)
+
+// A Reader reads the state of the external resource with the supplied
+// Terraform parameters natively from Vault. It should be considerably
+// cheaper than a Terraform refresh and change whenever the external resource
+// changes.
+type Reader func(ctx context.Context, c *vault.Client, params map[string]interface{}) (interface{}, error)
+
+// readers are the Readers of the Terraform resources whose polls may skip
+// Terraform, by the names of the resources.
+var readers = map[string]Reader{
+	"vault_generic_secret": genericSecret,
+}
+
+// NewClientFn returns a Vault client that uses the credentials of the
+// ProviderConfig of the supplied managed resource.
+type NewClientFn func(ctx context.Context, kube client.Client, mg xpresource.Managed) (*vault.Client, error)
+
+// NewConnector returns an ExternalConnecter that skips the Terraform runs of
+// the Terraform resource with the supplied name when its fingerprints did not
+// change, or the supplied ExternalConnecter itself if there is no Reader for
+// the resource.
+func NewConnector(c managed.ExternalConnecter, kube client.Client, newClientFn NewClientFn, resourceType string) managed.ExternalConnecter {
+	r, ok := readers[resourceType]
+	if !ok {
+		return c
+	}
+	return &connector{
+		wrapped:     c,
+		kube:        kube,
+		newClientFn: newClientFn,
+		read:        r,
+		records:     map[types.UID]record{},
+	}
+}
+
+// A record holds the fingerprints of the last poll Terraform found a managed
+// resource up to date in, along with the connection details it returned.
+type record struct {
+	desired  string
+	observed string
+	details  managed.ConnectionDetails
+}
+
+type connector struct {
+	wrapped     managed.ExternalConnecter
+	kube        client.Client
+	newClientFn NewClientFn
+	read        Reader
+
+	mu      sync.Mutex
+	records map[types.UID]record
+}
+
+func (c *connector) record(uid types.UID) (record, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.records[uid]
+	return r, ok
+}
+
+func (c *connector) store(uid types.UID, r record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records[uid] = r
+}
+
+func (c *connector) forget(uid types.UID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.records, uid)
+}
+
+// Connect defers connecting with the wrapped ExternalConnecter, which sets up
+// the Terraform workspace, until Terraform has to run.
+func (c *connector) Connect(_ context.Context, mg xpresource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(resource.Terraformed); !ok {
+		return nil, errors.New(errNotTerraformed)
+	}
+	return &external{connector: c}, nil
+}
+
+type external struct {
+	*connector
+	client managed.ExternalClient
+}
+
+func (e *external) connect(ctx context.Context, mg xpresource.Managed) (managed.ExternalClient, error) {
+	if e.client != nil {
+		return e.client, nil
+	}
+	ec, err := e.wrapped.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	e.client = ec
+	return ec, nil
+}
+
+func (e *external) Observe(ctx context.Context, mg xpresource.Managed) (managed.ExternalObservation, error) {
+	tr := mg.(resource.Terraformed)
+	// Resources that are not created yet or are being deleted always go
+	// through Terraform.
+	if meta.GetExternalName(tr) == "" || meta.WasDeleted(tr) {
+		e.forget(tr.GetUID())
+		return e.observe(ctx, tr, "", "")
+	}
+	desired, observed, err := e.fingerprints(ctx, tr)
+	if err != nil {
+		// The fingerprints are an optimization, Terraform decides if they
+		// cannot be computed.
+		e.forget(tr.GetUID())
+		return e.observe(ctx, tr, "", "")
+	}
+	if r, ok := e.record(tr.GetUID()); ok && r.desired == desired && r.observed == observed {
+		tr.SetConditions(xpv1.Available())
+		return managed.ExternalObservation{
+			ResourceExists:    true,
+			ResourceUpToDate:  true,
+			ConnectionDetails: r.details,
+		}, nil
+	}
+	return e.observe(ctx, tr, desired, observed)
+}
+
+// observe observes the supplied resource with Terraform and stores the
+// supplied fingerprints if it is up to date.
+func (e *external) observe(ctx context.Context, tr resource.Terraformed, desired, observed string) (managed.ExternalObservation, error) {
+	ec, err := e.connect(ctx, tr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	o, err := ec.Observe(ctx, tr)
+	if err != nil || desired == "" || !o.ResourceExists || !o.ResourceUpToDate || o.ResourceLateInitialized {
+		e.forget(tr.GetUID())
+		return o, err
+	}
+	e.store(tr.GetUID(), record{desired: desired, observed: observed, details: o.ConnectionDetails})
+	return o, nil
+}
+
+func (e *external) Create(ctx context.Context, mg xpresource.Managed) (managed.ExternalCreation, error) {
+	e.forget(mg.GetUID())
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return ec.Create(ctx, mg)
+}
+
+func (e *external) Update(ctx context.Context, mg xpresource.Managed) (managed.ExternalUpdate, error) {
+	e.forget(mg.GetUID())
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	return ec.Update(ctx, mg)
+}
+
+func (e *external) Delete(ctx context.Context, mg xpresource.Managed) error {
+	e.forget(mg.GetUID())
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return err
+	}
+	return ec.Delete(ctx, mg)
+}
+
+// fingerprints returns the fingerprints of the desired and of the observed
+// state of the supplied resource.
+func (e *external) fingerprints(ctx context.Context, tr resource.Terraformed) (string, string, error) {
+	params, err := tr.GetParameters()
+	if err != nil {
+		return "", "", errors.Wrap(err, errGetParameters)
+	}
+	secrets, err := e.secrets(ctx, tr)
+	if err != nil {
+		return "", "", err
+	}
+	desired, err := hash(struct {
+		ProviderConfig *xpv1.Reference
+		Parameters     map[string]interface{}
+		Secrets        map[string][]byte
+	}{ProviderConfig: tr.GetProviderConfigReference(), Parameters: params, Secrets: secrets})
+	if err != nil {
+		return "", "", err
+	}
+	vc, err := e.newClientFn(ctx, e.kube, tr)
+	if err != nil {
+		return "", "", err
+	}
+	state, err := e.read(ctx, vc, params)
+	if err != nil {
+		return "", "", err
+	}
+	observed, err := hash(state)
+	return desired, observed, err
+}
+
+// secrets returns the contents of the Secrets referenced by the sensitive
+// parameters of the supplied resource by the paths of their references.
+func (e *external) secrets(ctx context.Context, tr resource.Terraformed) (map[string][]byte, error) {
+	pv, err := fieldpath.PaveObject(tr)
+	if err != nil {
+		return nil, errors.Wrap(err, errPave)
+	}
+	paths := make([]string, 0, len(tr.GetConnectionDetailsMapping()))
+	for _, p := range tr.GetConnectionDetailsMapping() {
+		if strings.HasPrefix(p, prefixSpec) {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	result := map[string][]byte{}
+	for _, p := range paths {
+		expanded, err := pv.ExpandWildcards(p)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetSecretRef)
+		}
+		for _, ep := range expanded {
+			sel := xpv1.SecretKeySelector{}
+			if err := pv.GetValueInto(ep, &sel); err != nil {
+				return nil, errors.Wrap(err, errGetSecretRef)
+			}
+			s := &corev1.Secret{}
+			if err := clients.SecretReader(e.kube).Get(ctx, types.NamespacedName{Namespace: sel.Namespace, Name: sel.Name}, s); err != nil {
+				return nil, errors.Wrap(err, errGetSecret)
+			}
+			result[ep] = s.Data[sel.Key]
+		}
+	}
+	return result, nil
+}
+
+// hash returns the hex encoded SHA-256 sum of the JSON encoding of the
+// supplied value. Maps are encoded with sorted keys.
+func hash(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", errors.Wrap(err, errMarshal)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fingerprint
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+const (
+	errNoPath = "parameters have no path"
+	errRead   = "cannot read Vault API path"
+)
+
+// genericSecret reads the data at the path of a generic Secret. Reads of KV
+// version 2 paths also return the metadata of the current version, so any
+// write to the secret changes the result.
+func genericSecret(ctx context.Context, c *vault.Client, params map[string]interface{}) (interface{}, error) {
+	path, _ := params["path"].(string)
+	if path == "" {
+		return nil, errors.New(errNoPath)
+	}
+	s, err := c.Read(ctx, path)
+	if err != nil {
+		return nil, errors.Wrap(err, errRead)
+	}
+	if s == nil {
+		return nil, nil
+	}
+	return s.Data, nil
+}
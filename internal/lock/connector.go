@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lock
+
+import (
+	"context"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/resource"
+)
+
+// parameterPath is the parameter of the Terraform resources the provider
+// configures, i.e. vault_generic_secret, that holds the Vault path they
+// write to.
+const parameterPath = "path"
+
+// vaultPather is implemented by the hand-written managed resources and the
+// ones generated from the Vault OpenAPI document.
+type vaultPather interface {
+	GetVaultPath() string
+}
+
+// VaultPath returns the Vault path the supplied managed resource writes to,
+// which is empty if it writes to none, and false if it is not known.
+func VaultPath(mg xpresource.Managed) (string, bool) {
+	if o, ok := mg.(vaultPather); ok {
+		return o.GetVaultPath(), true
+	}
+	tr, ok := mg.(resource.Terraformed)
+	if !ok {
+		return "", false
+	}
+	params, err := tr.GetParameters()
+	if err != nil {
+		return "", false
+	}
+	if p, ok := params[parameterPath].(string); ok && p != "" {
+		return p, true
+	}
+	return "", false
+}
+
+// Path returns the Vault path the supplied managed resource writes to, or
+// its external name if it is not known.
+func Path(mg xpresource.Managed) string {
+	if p, ok := VaultPath(mg); ok && p != "" {
+		return p
+	}
+	return meta.GetExternalName(mg)
+}
+
+// NewConnector returns an ExternalConnecter whose ExternalClients hold the
+// lock of the Vault path and ProviderConfig of the resource in the supplied
+// Manager while they create, update or delete it. The supplied
+// ExternalConnecter is returned if there is no Manager.
+func NewConnector(c managed.ExternalConnecter, m *Manager) managed.ExternalConnecter {
+	if m == nil {
+		return c
+	}
+	return managed.ExternalConnectorFn(func(ctx context.Context, mg xpresource.Managed) (managed.ExternalClient, error) {
+		ec, err := c.Connect(ctx, mg)
+		if err != nil {
+			return nil, err
+		}
+		return &external{ExternalClient: ec, locks: m}, nil
+	})
+}
+
+type external struct {
+	managed.ExternalClient
+	locks *Manager
+}
+
+func (e *external) lock(ctx context.Context, mg xpresource.Managed) (func(), error) {
+	p := Path(mg)
+	if p == "" {
+		return func() {}, nil
+	}
+	k := Key{Path: p}
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		k.ProviderConfig = ref.Name
+	}
+	return e.locks.Lock(ctx, k)
+}
+
+func (e *external) Create(ctx context.Context, mg xpresource.Managed) (managed.ExternalCreation, error) {
+	unlock, err := e.lock(ctx, mg)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	defer unlock()
+	return e.ExternalClient.Create(ctx, mg)
+}
+
+func (e *external) Update(ctx context.Context, mg xpresource.Managed) (managed.ExternalUpdate, error) {
+	unlock, err := e.lock(ctx, mg)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	defer unlock()
+	return e.ExternalClient.Update(ctx, mg)
+}
+
+func (e *external) Delete(ctx context.Context, mg xpresource.Managed) error {
+	unlock, err := e.lock(ctx, mg)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return e.ExternalClient.Delete(ctx, mg)
+}
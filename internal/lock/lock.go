@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lock serializes the operations of managed resources that write to
+// the same Vault path with the same ProviderConfig, so that their
+// read-modify-write cycles do not race when resources are reconciled
+// concurrently.
+package lock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	errFmtTimeout = "cannot lock Vault path %q of ProviderConfig %q within %s"
+)
+
+var (
+	metricWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "path_lock",
+		Name:      "wait_seconds",
+		Help:      "Time operations waited for the lock of a Vault path.",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 15, 30, 60},
+	})
+	metricContended = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "path_lock",
+		Name:      "contended_total",
+		Help:      "Number of operations that found the lock of their Vault path held.",
+	})
+	metricTimeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "path_lock",
+		Name:      "timeouts_total",
+		Help:      "Number of operations that timed out waiting for the lock of a Vault path.",
+	})
+	metricHeld = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "path_lock",
+		Name:      "held",
+		Help:      "Number of Vault path locks that are held.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricWait, metricContended, metricTimeouts, metricHeld)
+}
+
+// A Key identifies a Vault path of a ProviderConfig.
+type Key struct {
+	ProviderConfig string
+	Path           string
+}
+
+func (k Key) less(o Key) bool {
+	if k.ProviderConfig != o.ProviderConfig {
+		return k.ProviderConfig < o.ProviderConfig
+	}
+	return k.Path < o.Path
+}
+
+// lock is the lock of a Key. Its channel holds a value while it is held.
+type lock struct {
+	held chan struct{}
+	refs int
+}
+
+// A Manager hands out locks by Key. Locks are only kept while they are held
+// or waited for.
+type Manager struct {
+	timeout time.Duration
+
+	mu    sync.Mutex
+	locks map[Key]*lock
+}
+
+// NewManager returns a Manager whose locks time out after the supplied
+// duration.
+func NewManager(timeout time.Duration) *Manager {
+	return &Manager{timeout: timeout, locks: map[Key]*lock{}}
+}
+
+// Lock waits until the locks of the supplied keys are held, or until the
+// timeout of the Manager expires. The locks are taken in a fixed order so
+// that callers locking overlapping keys cannot deadlock each other. The
+// returned function releases them.
+func (m *Manager) Lock(ctx context.Context, keys ...Key) (func(), error) {
+	sorted := make([]Key, 0, len(keys))
+	seen := map[Key]bool{}
+	for _, k := range keys {
+		if !seen[k] {
+			seen[k] = true
+			sorted = append(sorted, k)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].less(sorted[j]) })
+
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	held := make([]Key, 0, len(sorted))
+	unlock := func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			m.unlock(held[i])
+		}
+	}
+	for _, k := range sorted {
+		if err := m.lock(ctx, k); err != nil {
+			unlock()
+			return nil, err
+		}
+		held = append(held, k)
+	}
+	return unlock, nil
+}
+
+func (m *Manager) lock(ctx context.Context, k Key) error {
+	m.mu.Lock()
+	l, ok := m.locks[k]
+	if !ok {
+		l = &lock{held: make(chan struct{}, 1)}
+		m.locks[k] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case l.held <- struct{}{}:
+		metricWait.Observe(0)
+		metricHeld.Inc()
+		return nil
+	default:
+	}
+	metricContended.Inc()
+	select {
+	case l.held <- struct{}{}:
+		metricWait.Observe(time.Since(start).Seconds())
+		metricHeld.Inc()
+		return nil
+	case <-ctx.Done():
+		m.release(k, l)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			metricTimeouts.Inc()
+			return errors.Errorf(errFmtTimeout, k.Path, k.ProviderConfig, m.timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) unlock(k Key) {
+	m.mu.Lock()
+	l := m.locks[k]
+	m.mu.Unlock()
+	<-l.held
+	metricHeld.Dec()
+	m.release(k, l)
+}
+
+// release drops a reference to the supplied lock and forgets it once nobody
+// holds or waits for it.
+func (m *Manager) release(k Key, l *lock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(m.locks, k)
+	}
+}
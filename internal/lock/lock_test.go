@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lock
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	a := Key{ProviderConfig: "default", Path: "secret/a"}
+	b := Key{ProviderConfig: "default", Path: "secret/b"}
+	c := Key{ProviderConfig: "other", Path: "secret/a"}
+
+	cases := map[string]struct {
+		timeout time.Duration
+		held    []Key
+		keys    []Key
+		wantErr string
+	}{
+		"Free": {
+			timeout: time.Second,
+			keys:    []Key{a, b},
+		},
+		"DuplicateKeys": {
+			timeout: time.Second,
+			keys:    []Key{a, a},
+		},
+		"OtherProviderConfig": {
+			timeout: 50 * time.Millisecond,
+			held:    []Key{a},
+			keys:    []Key{c},
+		},
+		"Timeout": {
+			timeout: 50 * time.Millisecond,
+			held:    []Key{b},
+			keys:    []Key{a, b},
+			wantErr: `cannot lock Vault path "secret/b" of ProviderConfig "default" within 50ms`,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := NewManager(tc.timeout)
+			if len(tc.held) > 0 {
+				unlock, err := m.Lock(context.Background(), tc.held...)
+				if err != nil {
+					t.Fatalf("Lock(...): %s", err)
+				}
+				defer unlock()
+			}
+			unlock, err := m.Lock(context.Background(), tc.keys...)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Lock(...): error %v, want %q", err, tc.wantErr)
+				}
+				// The locks taken before the timeout are released.
+				if _, ok := m.locks[a]; ok {
+					t.Errorf("Lock(...): lock of %v left behind", a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lock(...): %s", err)
+			}
+			unlock()
+		})
+	}
+}
+
+func TestLockCancelled(t *testing.T) {
+	k := Key{ProviderConfig: "default", Path: "secret/a"}
+	m := NewManager(0)
+	unlock, _ := m.Lock(context.Background(), k)
+	defer unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.Lock(ctx, k); err != context.Canceled {
+		t.Errorf("Lock(...): error %v, want %v", err, context.Canceled)
+	}
+	if l := m.locks[k]; l.refs != 1 {
+		t.Errorf("Lock(...): %d references left, want 1", l.refs)
+	}
+}
+
+func TestLockOverlappingKeys(t *testing.T) {
+	a := Key{ProviderConfig: "default", Path: "secret/a"}
+	b := Key{ProviderConfig: "default", Path: "secret/b"}
+	c := Key{ProviderConfig: "default", Path: "secret/c"}
+	m := NewManager(10 * time.Second)
+
+	// Every key is only held by one caller at a time, and callers that lock
+	// overlapping keys in different orders do not deadlock each other.
+	holders := map[Key]*int32{a: new(int32), b: new(int32), c: new(int32)}
+	var wg sync.WaitGroup
+	errs := make(chan error, 300)
+	for i := 0; i < 100; i++ {
+		for _, keys := range [][]Key{{a, b}, {b, a}, {c, b, a}} {
+			keys := keys
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock, err := m.Lock(context.Background(), keys...)
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, k := range keys {
+					if n := atomic.AddInt32(holders[k], 1); n != 1 {
+						t.Errorf("Lock(...): %v held by %d callers", k, n)
+					}
+				}
+				time.Sleep(10 * time.Microsecond)
+				for _, k := range keys {
+					atomic.AddInt32(holders[k], -1)
+				}
+				unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Lock(...): %s", err)
+	}
+	if len(m.locks) != 0 {
+		t.Errorf("Lock(...): %d locks left behind after they were released", len(m.locks))
+	}
+}
+
+func TestUnlockForgetsLock(t *testing.T) {
+	k := Key{ProviderConfig: "default", Path: "secret/a"}
+	m := NewManager(time.Second)
+
+	unlock, err := m.Lock(context.Background(), k)
+	if err != nil {
+		t.Fatalf("Lock(...): %s", err)
+	}
+	waited := make(chan func())
+	go func() {
+		u, err := m.Lock(context.Background(), k)
+		if err != nil {
+			t.Errorf("Lock(...): %s", err)
+		}
+		waited <- u
+	}()
+	// The lock is kept while it is waited for.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		m.mu.Lock()
+		refs := m.locks[k].refs
+		m.mu.Unlock()
+		if refs == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Lock(...): %d references, want 2", refs)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	unlock()
+	(<-waited)()
+
+	if _, ok := m.locks[k]; ok {
+		t.Errorf("unlock(): lock of %v left behind", k)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// IsUpToDate returns true if every parameter equals the field with the same
+// name in the supplied read response data.
+func IsUpToDate(params, data map[string]interface{}) bool {
+	return len(ChangedParameters(params, data)) == 0
+}
+
+// ChangedParameters returns the sorted names of the parameters that differ
+// from the field with the same name in the supplied read response data.
+func ChangedParameters(params, data map[string]interface{}) []string {
+	var changed []string
+	for k, want := range params {
+		if !equal(want, data[k]) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func equal(want, got interface{}) bool {
+	switch w := want.(type) {
+	case []interface{}:
+		// Vault treats most lists as sets and does not preserve their order.
+		g, _ := got.([]interface{})
+		return equalSets(w, g)
+	case map[string]interface{}:
+		g, _ := got.(map[string]interface{})
+		if len(w) != len(g) {
+			return false
+		}
+		for k, v := range w {
+			if !equal(v, g[k]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		// Durations are accepted as strings but returned in seconds.
+		if g, ok := got.(float64); ok {
+			s, ok := seconds(w)
+			return ok && s == g
+		}
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+func equalSets(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := make([]string, len(a))
+	y := make([]string, len(b))
+	for i := range a {
+		x[i] = fmt.Sprint(a[i])
+		y[i] = fmt.Sprint(b[i])
+	}
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// seconds returns the number of seconds of a duration such as 1h or 3600.
+func seconds(s string) (float64, bool) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	d, err := time.ParseDuration(s)
+	return d.Seconds(), err == nil
+}
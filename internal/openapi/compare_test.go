@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChangedParameters(t *testing.T) {
+	cases := map[string]struct {
+		params map[string]interface{}
+		data   map[string]interface{}
+		want   []string
+	}{
+		"Equal": {
+			params: map[string]interface{}{"key": "default", "redirect_uris": []interface{}{"a", "b"}},
+			data:   map[string]interface{}{"key": "default", "redirect_uris": []interface{}{"a", "b"}},
+		},
+		"ComputedFieldsIgnored": {
+			params: map[string]interface{}{"key": "default"},
+			data:   map[string]interface{}{"key": "default", "client_id": "abc", "client_secret": "xyz"},
+		},
+		"MissingField": {
+			params: map[string]interface{}{"key": "default", "client_type": "confidential"},
+			data:   map[string]interface{}{"key": "default"},
+			want:   []string{"client_type"},
+		},
+		"ChangedField": {
+			params: map[string]interface{}{"key": "default", "client_type": "public"},
+			data:   map[string]interface{}{"key": "default", "client_type": "confidential"},
+			want:   []string{"client_type"},
+		},
+		"MissingNestedField": {
+			params: map[string]interface{}{"metadata": map[string]interface{}{"team": "a", "env": "prod"}},
+			data:   map[string]interface{}{"metadata": map[string]interface{}{"team": "a"}},
+			want:   []string{"metadata"},
+		},
+		"ExtraNestedField": {
+			params: map[string]interface{}{"metadata": map[string]interface{}{"team": "a"}},
+			data:   map[string]interface{}{"metadata": map[string]interface{}{"team": "a", "env": "prod"}},
+			want:   []string{"metadata"},
+		},
+		"ListInAnotherOrder": {
+			params: map[string]interface{}{"redirect_uris": []interface{}{"a", "b"}},
+			data:   map[string]interface{}{"redirect_uris": []interface{}{"b", "a"}},
+		},
+		"MissingListElement": {
+			params: map[string]interface{}{"redirect_uris": []interface{}{"a", "b"}},
+			data:   map[string]interface{}{"redirect_uris": []interface{}{"a"}},
+			want:   []string{"redirect_uris"},
+		},
+		"ExtraListElement": {
+			params: map[string]interface{}{"redirect_uris": []interface{}{"a"}},
+			data:   map[string]interface{}{"redirect_uris": []interface{}{"a", "b"}},
+			want:   []string{"redirect_uris"},
+		},
+		"DurationInSeconds": {
+			params: map[string]interface{}{"id_token_ttl": "1h", "access_token_ttl": "600"},
+			data:   map[string]interface{}{"id_token_ttl": float64(3600), "access_token_ttl": float64(600)},
+		},
+		"ChangedDuration": {
+			params: map[string]interface{}{"id_token_ttl": "1h", "access_token_ttl": "10m"},
+			data:   map[string]interface{}{"id_token_ttl": float64(1800), "access_token_ttl": float64(600)},
+			want:   []string{"id_token_ttl"},
+		},
+		"SortedNames": {
+			params: map[string]interface{}{"b": "x", "a": "x", "c": "x"},
+			data:   map[string]interface{}{"b": "y"},
+			want:   []string{"a", "b", "c"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ChangedParameters(tc.params, tc.data)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ChangedParameters(...): %v, want %v", got, tc.want)
+			}
+			if up := IsUpToDate(tc.params, tc.data); up != (len(tc.want) == 0) {
+				t.Errorf("IsUpToDate(...): %t, want %t", up, len(tc.want) == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients/vault"
+)
+
+const (
+	errNotObject      = "managed resource is not generated from the Vault OpenAPI document"
+	errNewClient      = "cannot create new Vault client"
+	errRead           = "cannot read Vault API path"
+	errWrite          = "cannot write Vault API path"
+	errDelete         = "cannot delete Vault API path"
+	errGetParameters  = "cannot get parameters"
+	errSetObservation = "cannot set observation"
+	errGetSecret      = "cannot get sensitive parameter secret"
+)
+
+// Operations are the operations the API path of a resource supports in
+// addition to read and write.
+type Operations struct {
+	// Delete is true if the path can be deleted. Otherwise the object is left
+	// in Vault when its managed resource is deleted.
+	Delete bool
+}
+
+// NewClientFn returns a Vault client that uses the credentials of the
+// ProviderConfig of the supplied managed resource.
+type NewClientFn func(ctx context.Context, kube client.Client, mg resource.Managed) (*vault.Client, error)
+
+// NewConnector returns a new ExternalConnecter of managed resources
+// generated from the Vault OpenAPI document.
+func NewConnector(kube client.Client, newClientFn NewClientFn, ops Operations) managed.ExternalConnecter {
+	return &connector{kube: kube, newClientFn: newClientFn, ops: ops}
+}
+
+type connector struct {
+	kube        client.Client
+	newClientFn NewClientFn
+	ops         Operations
+}
+
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(Object); !ok {
+		return nil, errors.New(errNotObject)
+	}
+	vc, err := c.newClientFn(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return &external{kube: c.kube, client: vc, ops: c.ops}, nil
+}
+
+type external struct {
+	kube   client.Client
+	client *vault.Client
+	ops    Operations
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, ok := mg.(Object)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotObject)
+	}
+	s, err := e.client.Read(ctx, o.GetVaultPath())
+	if vault.IsNotFound(err) || (err == nil && (s == nil || s.Data == nil)) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRead)
+	}
+	if err := o.SetObservation(s.Data); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSetObservation)
+	}
+	params, err := o.GetParameters()
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetParameters)
+	}
+	// Vault never returns sensitive parameters, so they are considered
+	// changed whenever one of their secrets has been updated since we last
+	// wrote them.
+	_, version, err := e.sensitive(ctx, o)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	conn := managed.ConnectionDetails{}
+	for _, k := range o.GetConnectionDetailsKeys() {
+		if _, ok := s.Data[k]; ok {
+			conn[k] = []byte(vault.String(s.Data, k))
+		}
+	}
+	o.SetConditions(xpv1.Available())
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  IsUpToDate(params, s.Data) && version == o.GetSensitiveParametersVersion(),
+		ConnectionDetails: conn,
+	}, nil
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	o, ok := mg.(Object)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotObject)
+	}
+	// The secret versions cannot be recorded in the status during creation,
+	// so the sensitive parameters are written once more by the first update.
+	body, _, err := e.body(ctx, o)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if _, err := e.client.Write(ctx, o.GetVaultPath(), body); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errWrite)
+	}
+	meta.SetExternalName(o, o.GetVaultPath())
+	return managed.ExternalCreation{ExternalNameAssigned: true}, nil
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	o, ok := mg.(Object)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotObject)
+	}
+	body, version, err := e.body(ctx, o)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if _, err := e.client.Write(ctx, o.GetVaultPath(), body); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errWrite)
+	}
+	o.SetSensitiveParametersVersion(version)
+	return managed.ExternalUpdate{}, nil
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	o, ok := mg.(Object)
+	if !ok {
+		return errors.New(errNotObject)
+	}
+	if !e.ops.Delete {
+		return nil
+	}
+	err := e.client.Delete(ctx, o.GetVaultPath())
+	return errors.Wrap(resource.Ignore(vault.IsNotFound, err), errDelete)
+}
+
+// body returns the request body of the supplied object including its
+// sensitive parameters, and the version of the secrets they are read from.
+func (e *external) body(ctx context.Context, o Object) (map[string]interface{}, string, error) {
+	body, err := o.GetParameters()
+	if err != nil {
+		return nil, "", errors.Wrap(err, errGetParameters)
+	}
+	sensitive, version, err := e.sensitive(ctx, o)
+	if err != nil {
+		return nil, "", err
+	}
+	for k, v := range sensitive {
+		body[k] = v
+	}
+	return body, version, nil
+}
+
+// sensitive returns the values of the sensitive parameters of the supplied
+// object and the resource versions of the secrets they are read from.
+func (e *external) sensitive(ctx context.Context, o Object) (map[string]string, string, error) {
+	refs := o.GetSensitiveParameters()
+	names := make([]string, 0, len(refs))
+	for n, ref := range refs {
+		if ref != nil {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	values := make(map[string]string, len(names))
+	versions := make([]string, 0, len(names))
+	for _, n := range names {
+		ref := refs[n]
+		s := &corev1.Secret{}
+		if err := clients.SecretReader(e.kube).Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, "", errors.Wrap(err, errGetSecret)
+		}
+		values[n] = string(s.Data[ref.Key])
+		versions = append(versions, n+"="+s.GetResourceVersion())
+	}
+	return values, strings.Join(versions, ","), nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errReadDocument   = "cannot read OpenAPI document"
+	errDecodeDocument = "cannot decode OpenAPI document"
+	fmtUnknownRef     = "unknown schema reference %s"
+	refPrefix         = "#/components/schemas/"
+)
+
+// document is the subset of an OpenAPI 3 document that Vault generates and
+// the generator needs.
+type document struct {
+	Paths      map[string]*pathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Description string       `json:"description"`
+	Parameters  []*parameter `json:"parameters"`
+	Get         *operation   `json:"get"`
+	Post        *operation   `json:"post"`
+	Delete      *operation   `json:"delete"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	RequestBody *body                `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type body struct {
+	Content map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref          string             `json:"$ref"`
+	Type         string             `json:"type"`
+	Format       string             `json:"format"`
+	Description  string             `json:"description"`
+	Items        *schema            `json:"items"`
+	Properties   map[string]*schema `json:"properties"`
+	Required     []string           `json:"required"`
+	Enum         []interface{}      `json:"enum"`
+	Deprecated   bool               `json:"deprecated"`
+	DisplayAttrs *displayAttrs      `json:"x-vault-displayAttrs"`
+}
+
+type displayAttrs struct {
+	Name      string `json:"name"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+func (s *schema) sensitive() bool {
+	return s.DisplayAttrs != nil && s.DisplayAttrs.Sensitive
+}
+
+func loadDocument(path string) (*document, error) {
+	b, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadDocument)
+	}
+	d := &document{}
+	return d, errors.Wrap(json.Unmarshal(b, d), errDecodeDocument)
+}
+
+// resolve returns the schema the supplied one refers to, if any.
+func (d *document) resolve(s *schema) (*schema, error) {
+	if s == nil || s.Ref == "" {
+		return s, nil
+	}
+	r, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+	if !ok {
+		return nil, errors.Errorf(fmtUnknownRef, s.Ref)
+	}
+	return r, nil
+}
+
+// requestSchema returns the schema of the JSON body of the supplied
+// operation, if any.
+func (d *document) requestSchema(op *operation) (*schema, error) {
+	if op == nil || op.RequestBody == nil || op.RequestBody.Content["application/json"] == nil {
+		return nil, nil
+	}
+	return d.resolve(op.RequestBody.Content["application/json"].Schema)
+}
+
+// responseSchema returns the schema of the JSON body of the successful
+// response of the supplied operation, if any.
+func (d *document) responseSchema(op *operation) (*schema, error) {
+	if op == nil || op.Responses["200"] == nil || op.Responses["200"].Content["application/json"] == nil {
+		return nil, nil
+	}
+	return d.resolve(op.Responses["200"].Content["application/json"].Schema)
+}
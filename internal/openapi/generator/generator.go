@@ -0,0 +1,371 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generator generates managed resources from the Vault OpenAPI
+// document. Every configured API path becomes a CRD whose parameters are the
+// request body of its write operation and whose observation is the response
+// body of its read operation. Sensitive request fields become secret
+// references and sensitive response fields become connection details.
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/crossplane/terrajet/pkg/types/name"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-jet-vault/config/openapi"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/openapi/generator/templates"
+)
+
+const (
+	// GenStatement is the statement generated files start with.
+	GenStatement = "// Code generated by openapigen. DO NOT EDIT."
+
+	rootGroup = "vault.jet.crossplane.io"
+
+	errReadHeader      = "cannot read license header"
+	errReadGoMod       = "cannot read go.mod"
+	errNoModule        = "cannot find module path in go.mod"
+	errParseTemplate   = "cannot parse template"
+	errExecTemplate    = "cannot execute template"
+	errFormat          = "cannot format generated source"
+	errWriteFile       = "cannot write generated file"
+	fmtNoPath          = "path %s is not in the OpenAPI document"
+	fmtNoOperation     = "path %s has no %s operation"
+	fmtUnsupportedType = "field %s has unsupported type %q"
+	fmtGenerate        = "cannot generate resource %s"
+)
+
+var pathParameter = regexp.MustCompile(`{([^}]+)}`)
+
+// A field is a field of a generated struct.
+type field struct {
+	Name     string
+	Type     string
+	JSONTag  string
+	VaultTag string
+	Comment  []string
+	Markers  []string
+}
+
+// A sensitiveField is a parameter that is read from a secret.
+type sensitiveField struct {
+	Name      string
+	VaultName string
+}
+
+// A crd is a managed resource generated from an API path.
+type crd struct {
+	Kind           string
+	ShortGroup     string
+	Group          string
+	Version        string
+	Package        string
+	Description    []string
+	PathFormat     string
+	PathParameters []string
+	Parameters     []field
+	Observation    []field
+	Sensitive      []sensitiveField
+	ConnectionKeys []string
+	Delete         bool
+}
+
+// Run generates the configured resources from the Vault OpenAPI document of
+// the repository at the supplied root directory.
+func Run(rootDir string) error {
+	modulePath, err := readModulePath(rootDir)
+	if err != nil {
+		return err
+	}
+	header, err := ioutil.ReadFile(filepath.Join(rootDir, "hack", "boilerplate.go.txt"))
+	if err != nil {
+		return errors.Wrap(err, errReadHeader)
+	}
+	doc, err := loadDocument(filepath.Join(rootDir, openapi.DocumentPath))
+	if err != nil {
+		return err
+	}
+	groups := map[string]bool{}
+	for _, r := range openapi.Selected() {
+		c, err := newCRD(doc, r)
+		if err != nil {
+			return errors.Wrapf(err, fmtGenerate, r.Kind)
+		}
+		vars := struct {
+			*crd
+			Header       string
+			GenStatement string
+			ModulePath   string
+		}{crd: c, Header: strings.TrimSpace(string(header)), GenStatement: GenStatement, ModulePath: modulePath}
+
+		apiDir := filepath.Join(rootDir, r.APIPackage())
+		files := map[string]string{
+			filepath.Join(apiDir, "zz_"+c.Package+"_types.go"):                templates.TypesTemplate,
+			filepath.Join(apiDir, "zz_"+c.Package+"_openapi.go"):              templates.ObjectTemplate,
+			filepath.Join(rootDir, r.ControllerPackage(), "zz_controller.go"): templates.ControllerTemplate,
+		}
+		if !groups[r.ShortGroup] {
+			files[filepath.Join(apiDir, "zz_groupversion_info.go")] = templates.GroupVersionInfoTemplate
+			groups[r.ShortGroup] = true
+		}
+		for path, tmpl := range files {
+			if err := write(path, tmpl, vars); err != nil {
+				return errors.Wrapf(err, fmtGenerate, r.Kind)
+			}
+		}
+	}
+	return nil
+}
+
+func newCRD(doc *document, r openapi.Resource) (*crd, error) { // nolint:gocyclo
+	// The fields of the request and response schemas are handled one after
+	// the other, splitting them up would not make this easier to follow.
+	p, ok := doc.Paths[r.Path]
+	if !ok {
+		return nil, errors.Errorf(fmtNoPath, r.Path)
+	}
+	if p.Get == nil {
+		return nil, errors.Errorf(fmtNoOperation, r.Path, "read")
+	}
+	if p.Post == nil {
+		return nil, errors.Errorf(fmtNoOperation, r.Path, "write")
+	}
+	c := &crd{
+		Kind:        r.Kind,
+		ShortGroup:  r.ShortGroup,
+		Group:       r.ShortGroup + "." + rootGroup,
+		Version:     openapi.Version,
+		Package:     strings.ToLower(r.Kind),
+		Description: lines(p.Description),
+		PathFormat:  pathParameter.ReplaceAllString(strings.TrimPrefix(r.Path, "/"), "%s"),
+		Delete:      p.Delete != nil,
+	}
+
+	descriptions := map[string]string{}
+	for _, param := range p.Parameters {
+		if param.In == "path" {
+			descriptions[param.Name] = param.Description
+		}
+	}
+	inPath := map[string]bool{}
+	for _, m := range pathParameter.FindAllStringSubmatch(r.Path, -1) {
+		n := name.NewFromSnake(strings.ReplaceAll(m[1], "-", "_"))
+		inPath[m[1]] = true
+		c.PathParameters = append(c.PathParameters, n.Camel)
+		c.Parameters = append(c.Parameters, field{
+			Name:     n.Camel,
+			Type:     "string",
+			JSONTag:  n.LowerCamelComputed,
+			VaultTag: "-",
+			Comment:  lines(descriptions[m[1]]),
+			Markers:  []string{"+kubebuilder:validation:Required"},
+		})
+	}
+
+	req, err := doc.requestSchema(p.Post)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range properties(req) {
+		s, err := doc.resolve(req.Properties[k])
+		if err != nil {
+			return nil, err
+		}
+		if inPath[k] || s.Deprecated {
+			continue
+		}
+		n := name.NewFromSnake(strings.ReplaceAll(k, "-", "_"))
+		required := contains(req.Required, k)
+		f := field{Comment: lines(s.Description), Markers: []string{"+kubebuilder:validation:Optional"}}
+		if required {
+			f.Markers = []string{"+kubebuilder:validation:Required"}
+		}
+		if s.sensitive() {
+			f.Name = n.Camel + "SecretRef"
+			f.Type = "*v1.SecretKeySelector"
+			f.JSONTag = n.LowerCamelComputed + "SecretRef" + omitEmpty(required)
+			f.VaultTag = "-"
+			c.Parameters = append(c.Parameters, f)
+			c.Sensitive = append(c.Sensitive, sensitiveField{Name: f.Name, VaultName: k})
+			continue
+		}
+		if f.Type, err = goType(k, s); err != nil {
+			return nil, err
+		}
+		if e := enum(s); e != "" {
+			f.Markers = append(f.Markers, "+kubebuilder:validation:Enum="+e)
+		}
+		f.Name = n.Camel
+		f.JSONTag = n.LowerCamelComputed + omitEmpty(required)
+		f.VaultTag = k + ",omitempty"
+		c.Parameters = append(c.Parameters, f)
+	}
+
+	resp, err := doc.responseSchema(p.Get)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range properties(resp) {
+		s, err := doc.resolve(resp.Properties[k])
+		if err != nil {
+			return nil, err
+		}
+		if s.sensitive() {
+			c.ConnectionKeys = append(c.ConnectionKeys, k)
+			continue
+		}
+		t, err := goType(k, s)
+		if err != nil {
+			return nil, err
+		}
+		n := name.NewFromSnake(strings.ReplaceAll(k, "-", "_"))
+		c.Observation = append(c.Observation, field{
+			Name:     n.Camel,
+			Type:     t,
+			JSONTag:  n.LowerCamelComputed + ",omitempty",
+			VaultTag: k + ",omitempty",
+			Comment:  lines(s.Description),
+		})
+	}
+	return c, nil
+}
+
+func goType(n string, s *schema) (string, error) {
+	switch s.Type {
+	case "string":
+		return "*string", nil
+	case "integer":
+		return "*int64", nil
+	case "number":
+		return "*float64", nil
+	case "boolean":
+		return "*bool", nil
+	case "object":
+		return "map[string]string", nil
+	case "array":
+		if s.Items == nil {
+			return "[]string", nil
+		}
+		switch s.Items.Type {
+		case "string", "":
+			return "[]string", nil
+		case "integer":
+			return "[]int64", nil
+		case "number":
+			return "[]float64", nil
+		case "boolean":
+			return "[]bool", nil
+		}
+		return "", errors.Errorf(fmtUnsupportedType, n, "array of "+s.Items.Type)
+	}
+	return "", errors.Errorf(fmtUnsupportedType, n, s.Type)
+}
+
+// enum returns the allowed values of a string schema in the format of the
+// kubebuilder enum marker.
+func enum(s *schema) string {
+	values := make([]string, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		if str, ok := v.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return strings.Join(values, ";")
+}
+
+func omitEmpty(required bool) string {
+	if required {
+		return ""
+	}
+	return ",omitempty"
+}
+
+func properties(s *schema) []string {
+	if s == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// lines splits a description into comment lines.
+func lines(s string) []string {
+	var result []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func write(path, tmpl string, vars interface{}) error {
+	t, err := template.New(filepath.Base(path)).Parse(tmpl)
+	if err != nil {
+		return errors.Wrap(err, errParseTemplate)
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, vars); err != nil {
+		return errors.Wrap(err, errExecTemplate)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, errFormat)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return errors.Wrap(err, errWriteFile)
+	}
+	return errors.Wrap(ioutil.WriteFile(path, src, 0644), errWriteFile) // nolint:gosec
+}
+
+func readModulePath(rootDir string) (string, error) {
+	f, err := os.Open(filepath.Join(rootDir, "go.mod"))
+	if err != nil {
+		return "", errors.Wrap(err, errReadGoMod)
+	}
+	defer f.Close() // nolint:errcheck
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if l := strings.TrimSpace(s.Text()); strings.HasPrefix(l, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(l, "module ")), nil
+		}
+	}
+	return "", errors.New(errNoModule)
+}
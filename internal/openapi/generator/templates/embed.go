@@ -0,0 +1,38 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package templates contains the templates of the files generated from the
+// Vault OpenAPI document.
+package templates
+
+import _ "embed" // nolint:golint
+
+// GroupVersionInfoTemplate is populated with group and version information.
+//go:embed groupversion_info.go.tmpl
+var GroupVersionInfoTemplate string
+
+// TypesTemplate is populated with CRD and type information.
+//go:embed types.go.tmpl
+var TypesTemplate string
+
+// ObjectTemplate is populated with the methods implementing the
+// openapi.Object interface on CRD structs.
+//go:embed object.go.tmpl
+var ObjectTemplate string
+
+// ControllerTemplate is populated with controller setup functions.
+//go:embed controller.go.tmpl
+var ControllerTemplate string
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi contains the runtime of the managed resources generated
+// from the Vault OpenAPI document, which are reconciled by reading and writing
+// their API path directly.
+package openapi
+
+import (
+	jsoniter "github.com/json-iterator/go"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Parser converts between the parameters and observations of a generated
+// resource and the data of Vault API requests and responses using the vault
+// struct tags.
+var Parser = jsoniter.Config{TagKey: "vault"}.Froze()
+
+// An Object is a managed resource generated from the Vault OpenAPI document.
+type Object interface {
+	resource.Managed
+
+	// GetVaultPath returns the API path of the object.
+	GetVaultPath() string
+
+	// GetParameters returns the parameters of the object as the body of
+	// create and update requests, without its sensitive parameters.
+	GetParameters() (map[string]interface{}, error)
+
+	// GetSensitiveParameters returns the secret key references of the
+	// sensitive parameters of the object keyed by parameter name.
+	GetSensitiveParameters() map[string]*xpv1.SecretKeySelector
+
+	// GetSensitiveParametersVersion returns the version of the referenced
+	// secrets the sensitive parameters were last written from.
+	GetSensitiveParametersVersion() string
+
+	// SetSensitiveParametersVersion sets the version of the referenced
+	// secrets the sensitive parameters were last written from.
+	SetSensitiveParametersVersion(v string)
+
+	// SetObservation sets the observation of the object from the data of a
+	// read response.
+	SetObservation(data map[string]interface{}) error
+
+	// GetConnectionDetailsKeys returns the fields of a read response that
+	// are published as connection details since they are sensitive.
+	GetConnectionDetailsKeys() []string
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package poll adapts the intervals managed resources are polled at to how
+// often they drift, and exempts reconciles caused by changes of their specs
+// or of the Secrets they reference from the rate limit of routine drift
+// checks. The exemption is no priority: they still wait in the workqueue of
+// their controller, first in first out. Resources whose desired state
+// changes with time are polled by their deadlines, see WithDeadline; the
+// expiry of resources is enforced by the expiry package, which requeues them
+// by the time they expire.
+package poll
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	xpcontroller "github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	fieldForProvider = "forProvider"
+	suffixSecretRef  = "SecretRef"
+)
+
+// Limits bound the intervals managed resources are polled at.
+type Limits struct {
+	// Min is the interval resources that drift often are polled at.
+	Min time.Duration
+	// Max is the interval resources that do not drift are polled at.
+	Max time.Duration
+}
+
+// around returns the limits extended to include the supplied interval.
+func (l Limits) around(base time.Duration) Limits {
+	if l.Min <= 0 || l.Min > base {
+		l.Min = base
+	}
+	if l.Max < base {
+		l.Max = base
+	}
+	return l
+}
+
+// A DeadlineFn returns the time the desired state of the supplied managed
+// resource changes at, e.g. the time a token has to be refreshed at, and
+// false if it does not change with time.
+type DeadlineFn func(mg resource.Managed) (time.Time, bool)
+
+// An Option configures a Poller.
+type Option func(*Poller)
+
+// WithDeadline returns an Option that polls managed resources by the
+// deadlines the supplied function returns for them, no matter how long
+// they have not drifted.
+func WithDeadline(fn DeadlineFn) Option {
+	return func(p *Poller) {
+		p.deadline = fn
+	}
+}
+
+// state is what a Poller knows about a managed resource.
+type state struct {
+	interval   time.Duration
+	deadline   time.Time
+	generation int64
+	changed    bool
+	drifted    bool
+	exempt     bool
+	secrets    []types.NamespacedName
+}
+
+// A Poller adapts the poll intervals of the managed resources of one
+// controller. The interval of a resource is halved, down to the minimum,
+// every time it is found drifted, and doubled, up to the maximum, every time
+// it is not.
+type Poller struct {
+	kube        client.Client
+	newManaged  func() resource.Managed
+	base        time.Duration
+	limits      Limits
+	rateLimiter workqueue.RateLimiter
+	deadline    DeadlineFn
+
+	mu      sync.Mutex
+	states  map[types.NamespacedName]*state
+	secrets map[types.NamespacedName]map[types.NamespacedName]struct{}
+}
+
+// New returns a Poller of the managed resources of the supplied kind whose
+// intervals are bound by the supplied limits. They are polled at the poll
+// interval of the supplied options if no limits are set.
+func New(mgr ctrl.Manager, of resource.ManagedKind, o xpcontroller.Options, l Limits, opts ...Option) *Poller {
+	p := &Poller{
+		kube: mgr.GetClient(),
+		newManaged: func() resource.Managed {
+			return resource.MustCreateObject(schema.GroupVersionKind(of), mgr.GetScheme()).(resource.Managed)
+		},
+		base:        o.PollInterval,
+		limits:      l.around(o.PollInterval),
+		rateLimiter: o.GlobalRateLimiter,
+		states:      map[types.NamespacedName]*state{},
+		secrets:     map[types.NamespacedName]map[types.NamespacedName]struct{}{},
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Connecter returns an ExternalConnecter that records the resources the
+// supplied ExternalConnecter updates as drifted.
+func (p *Poller) Connecter(c managed.ExternalConnecter) managed.ExternalConnecter {
+	return managed.ExternalConnectorFn(func(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+		ec, err := c.Connect(ctx, mg)
+		if err != nil {
+			return nil, err
+		}
+		return &external{ExternalClient: ec, poller: p}, nil
+	})
+}
+
+// Reconciler returns a Reconciler that rate limits the routine polls of the
+// supplied Reconciler with the global rate limiter, lets the other reconciles
+// skip the rate limiter, and adapts the interval of the next poll.
+func (p *Poller) Reconciler(name string, r reconcile.Reconciler) reconcile.Reconciler {
+	limited := ratelimiter.NewReconciler(name, r, p.rateLimiter)
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		mg := p.newManaged()
+		if err := p.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+			if kerrors.IsNotFound(err) {
+				p.forget(req.NamespacedName)
+			}
+			return limited.Reconcile(ctx, req)
+		}
+		inner := reconcile.Reconciler(limited)
+		if p.exempt(req.NamespacedName, mg) {
+			inner = r
+		}
+		res, err := inner.Reconcile(ctx, req)
+		// The managed reconciler requeues after its poll interval only if
+		// it observed the external resource successfully.
+		if err == nil && res.RequeueAfter == p.base {
+			res.RequeueAfter = p.next(req.NamespacedName)
+		}
+		return res, err
+	})
+}
+
+// SecretHandler returns an event handler that enqueues the managed resources
+// that reference a Secret when the Secret changes, exempt from the rate
+// limiter.
+func (p *Poller) SecretHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		refs := p.secrets[types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}]
+		reqs := make([]reconcile.Request, 0, len(refs))
+		for nn := range refs {
+			if s, ok := p.states[nn]; ok {
+				s.exempt = true
+			}
+			reqs = append(reqs, reconcile.Request{NamespacedName: nn})
+		}
+		return reqs
+	})
+}
+
+// exempt records the supplied managed resource and returns true if it is
+// reconciled because it or a Secret it references changed, or because it is
+// being deleted.
+func (p *Poller) exempt(nn types.NamespacedName, mg resource.Managed) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.states[nn]
+	if !ok {
+		// Resources that are seen for the first time, e.g. after the
+		// provider started, are not exempt so that they cannot starve
+		// actual changes.
+		s = &state{interval: p.base, generation: mg.GetGeneration()}
+		p.states[nn] = s
+	}
+	p.index(nn, s, SecretRefs(mg))
+	s.changed = s.generation != mg.GetGeneration()
+	exempt := s.exempt || s.changed || meta.WasDeleted(mg)
+	s.exempt = false
+	s.generation = mg.GetGeneration()
+	return exempt
+}
+
+// index updates the Secrets the supplied managed resource references.
+func (p *Poller) index(nn types.NamespacedName, s *state, refs []types.NamespacedName) {
+	for _, ref := range s.secrets {
+		delete(p.secrets[ref], nn)
+		if len(p.secrets[ref]) == 0 {
+			delete(p.secrets, ref)
+		}
+	}
+	for _, ref := range refs {
+		if p.secrets[ref] == nil {
+			p.secrets[ref] = map[types.NamespacedName]struct{}{}
+		}
+		p.secrets[ref][nn] = struct{}{}
+	}
+	s.secrets = refs
+}
+
+func (p *Poller) forget(nn types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.states[nn]; ok {
+		p.index(nn, s, nil)
+		delete(p.states, nn)
+	}
+}
+
+// observed records the deadline of the supplied resource as of its last
+// observation or update.
+func (p *Poller) observed(mg resource.Managed) {
+	if p.deadline == nil {
+		return
+	}
+	t, ok := p.deadline(mg)
+	if !ok {
+		t = time.Time{}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.states[types.NamespacedName{Namespace: mg.GetNamespace(), Name: mg.GetName()}]; ok {
+		s.deadline = t
+	}
+}
+
+// drifted records that the supplied resource is being updated. Updates that
+// apply a change of its spec are not drift.
+func (p *Poller) drifted(nn types.NamespacedName) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s, ok := p.states[nn]; ok && !s.changed {
+		s.drifted = true
+	}
+}
+
+// next returns the interval to poll the supplied resource after.
+func (p *Poller) next(nn types.NamespacedName) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.states[nn]
+	if !ok {
+		return p.base
+	}
+	if s.drifted {
+		s.interval /= 2
+	} else {
+		s.interval *= 2
+	}
+	s.drifted, s.changed = false, false
+	if s.interval < p.limits.Min {
+		s.interval = p.limits.Min
+	}
+	if s.interval > p.limits.Max {
+		s.interval = p.limits.Max
+	}
+	if s.deadline.IsZero() {
+		return s.interval
+	}
+	// Requeue a moment after the deadline rather than right before it.
+	if d := time.Until(s.deadline) + time.Second; d < s.interval {
+		if d < time.Second {
+			return time.Second
+		}
+		return d
+	}
+	return s.interval
+}
+
+// SecretRefs returns the Secrets referenced by the SecretRef fields of the
+// parameters of the supplied managed resource.
+func SecretRefs(mg resource.Managed) []types.NamespacedName {
+	pv, err := fieldpath.PaveObject(mg)
+	if err != nil {
+		return nil
+	}
+	spec, err := pv.GetValue("spec." + fieldForProvider)
+	if err != nil {
+		return nil
+	}
+	var refs []types.NamespacedName
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, f := range t {
+				if ref, ok := f.(map[string]interface{}); ok && strings.HasSuffix(k, suffixSecretRef) {
+					ns, _ := ref["namespace"].(string)
+					n, _ := ref["name"].(string)
+					if n != "" {
+						refs = append(refs, types.NamespacedName{Namespace: ns, Name: n})
+					}
+					continue
+				}
+				walk(f)
+			}
+		case []interface{}:
+			for _, e := range t {
+				walk(e)
+			}
+		}
+	}
+	walk(spec)
+	return refs
+}
+
+// external records the resources that are updated as drifted, and the
+// deadlines of the resources it observes and updates.
+type external struct {
+	managed.ExternalClient
+	poller *Poller
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	e.poller.observed(mg)
+	return o, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	e.poller.drifted(types.NamespacedName{Namespace: mg.GetNamespace(), Name: mg.GetName()})
+	u, err := e.ExternalClient.Update(ctx, mg)
+	e.poller.observed(mg)
+	return u, err
+}
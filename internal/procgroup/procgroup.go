@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package procgroup runs commands in process groups of their own, so that
+// the processes they start, such as the provider plugins Terraform starts,
+// are killed with them.
+package procgroup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	osexec "os/exec"
+	"sync"
+	"time"
+
+	"k8s.io/utils/exec"
+)
+
+// stopGrace is the time a stopped process group has to exit before it is
+// killed.
+const stopGrace = 10 * time.Second
+
+// New returns an executor whose commands run in process groups of their own.
+// When the context of a command is done, its whole process group is killed.
+func New() exec.Interface {
+	return &executor{}
+}
+
+type executor struct{}
+
+func (e *executor) Command(cmd string, args ...string) exec.Cmd {
+	return e.CommandContext(context.Background(), cmd, args...)
+}
+
+func (e *executor) CommandContext(ctx context.Context, cmd string, args ...string) exec.Cmd {
+	c := osexec.Command(cmd, args...)
+	setpgid(c)
+	return &command{cmd: c, ctx: ctx}
+}
+
+func (e *executor) LookPath(file string) (string, error) {
+	p, err := osexec.LookPath(file)
+	return p, convert(err)
+}
+
+// command is a command that runs in a process group of its own.
+type command struct {
+	cmd *osexec.Cmd
+	ctx context.Context
+
+	// done is closed once the command was waited for.
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *command) SetDir(dir string) {
+	c.cmd.Dir = dir
+}
+
+func (c *command) SetStdin(in io.Reader) {
+	c.cmd.Stdin = in
+}
+
+func (c *command) SetStdout(out io.Writer) {
+	c.cmd.Stdout = out
+}
+
+func (c *command) SetStderr(out io.Writer) {
+	c.cmd.Stderr = out
+}
+
+func (c *command) SetEnv(env []string) {
+	c.cmd.Env = env
+}
+
+func (c *command) StdoutPipe() (io.ReadCloser, error) {
+	r, err := c.cmd.StdoutPipe()
+	return r, convert(err)
+}
+
+func (c *command) StderrPipe() (io.ReadCloser, error) {
+	r, err := c.cmd.StderrPipe()
+	return r, convert(err)
+}
+
+// Start starts the command and kills its process group once its context is
+// done, unless it was waited for before.
+func (c *command) Start() error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if err := c.cmd.Start(); err != nil {
+		return convert(err)
+	}
+	c.done = make(chan struct{})
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			signal(c.cmd.Process, true)
+		case <-c.done:
+		}
+	}()
+	return nil
+}
+
+func (c *command) Wait() error {
+	err := c.cmd.Wait()
+	if c.done != nil {
+		c.once.Do(func() { close(c.done) })
+	}
+	return convert(err)
+}
+
+func (c *command) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+func (c *command) CombinedOutput() ([]byte, error) {
+	b := &bytes.Buffer{}
+	c.cmd.Stdout = b
+	c.cmd.Stderr = b
+	err := c.Run()
+	return b.Bytes(), err
+}
+
+func (c *command) Output() ([]byte, error) {
+	b := &bytes.Buffer{}
+	c.cmd.Stdout = b
+	err := c.Run()
+	return b.Bytes(), err
+}
+
+// Stop terminates the process group of the command and kills it if it did
+// not exit after a grace period.
+func (c *command) Stop() {
+	if c.cmd.Process == nil {
+		return
+	}
+	signal(c.cmd.Process, false)
+	time.AfterFunc(stopGrace, func() {
+		select {
+		case <-c.done:
+		default:
+			signal(c.cmd.Process, true)
+		}
+	})
+}
+
+// convert converts the supplied error to the errors of the exec package, so
+// that callers can tell the exit status of a command.
+func convert(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch e := err.(type) {
+	case *osexec.ExitError:
+		return &exec.ExitErrorWrapper{ExitError: e}
+	case *osexec.Error:
+		if e.Err == osexec.ErrNotFound {
+			return exec.ErrExecutableNotFound
+		}
+	}
+	return err
+}
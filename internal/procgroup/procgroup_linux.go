@@ -0,0 +1,41 @@
+//go:build linux
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package procgroup
+
+import (
+	"os"
+	osexec "os/exec"
+	"syscall"
+)
+
+// setpgid makes the supplied command start a process group of its own.
+func setpgid(c *osexec.Cmd) {
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signal terminates, or kills, the process group led by the supplied
+// process.
+func signal(p *os.Process, kill bool) {
+	sig := syscall.SIGTERM
+	if kill {
+		sig = syscall.SIGKILL
+	}
+	// The process group may have exited already.
+	_ = syscall.Kill(-p.Pid, sig)
+}
@@ -0,0 +1,119 @@
+//go:build linux
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package procgroup
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/utils/exec"
+)
+
+// alive returns true if the process with the supplied PID runs and is not a
+// zombie.
+func alive(pid int) bool {
+	raw, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	// The state follows the parenthesized command name.
+	fields := strings.Fields(string(raw[strings.LastIndexByte(string(raw), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestCancelKillsProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := New().CommandContext(ctx, "sh", "-c", "sleep 60 & echo $!; wait")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe(): %s", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start(): %s", err)
+	}
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString(...): %s", err)
+	}
+	child, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		t.Fatalf("Atoi(...): %s", err)
+	}
+
+	cancel()
+	err = cmd.Wait()
+	if ee, ok := err.(exec.ExitError); !ok || ee.Exited() {
+		t.Errorf("Wait(): error %v, want the command to be killed", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for alive(child) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Wait(): child process %d outlived its command", child)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases := map[string]struct {
+		args       []string
+		wantOut    string
+		wantStatus int
+	}{
+		"Succeeds": {
+			args:    []string{"-c", "echo out; echo err >&2"},
+			wantOut: "out\nerr\n",
+		},
+		"Fails": {
+			args:       []string{"-c", "echo out; exit 3"},
+			wantOut:    "out\n",
+			wantStatus: 3,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			out, err := New().CommandContext(context.Background(), "sh", tc.args...).CombinedOutput()
+			if string(out) != tc.wantOut {
+				t.Errorf("CombinedOutput(): output %q, want %q", out, tc.wantOut)
+			}
+			status := 0
+			if ee, ok := err.(exec.ExitError); ok {
+				status = ee.ExitStatus()
+			} else if err != nil {
+				t.Fatalf("CombinedOutput(): %s", err)
+			}
+			if status != tc.wantStatus {
+				t.Errorf("CombinedOutput(): exit status %d, want %d", status, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	err := New().Command("provider-jet-vault-does-not-exist").Run()
+	if err != exec.ErrExecutableNotFound {
+		t.Errorf("Run(): error %v, want %v", err, exec.ErrExecutableNotFound)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !linux
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package procgroup
+
+import (
+	"os"
+	osexec "os/exec"
+)
+
+// setpgid leaves the supplied command in the process group of the provider,
+// process groups are only used on Linux.
+func setpgid(_ *osexec.Cmd) {}
+
+// signal kills the supplied process, the processes it started are left
+// running.
+func signal(p *os.Process, _ bool) {
+	// The process may have exited already.
+	_ = p.Kill()
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package render renders the Terraform configuration the provider produces
+// for managed resources, without a Kubernetes cluster, so that issues can be
+// reproduced locally.
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	tjconfig "github.com/crossplane/terrajet/pkg/config"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/terraform"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+)
+
+// Masked replaces sensitive values in rendered output.
+const Masked = "(sensitive)"
+
+// vaultToken matches the service, batch and recovery tokens of current and
+// earlier Vault versions.
+var vaultToken = regexp.MustCompile(`\b(hv[sbr]\.[A-Za-z0-9_-]{20,}|[sbr]\.[A-Za-z0-9]{24})\b`)
+
+// RedactTokens replaces the Vault tokens in the supplied text.
+func RedactTokens(text []byte) []byte {
+	return vaultToken.ReplaceAll(text, []byte(Masked))
+}
+
+const (
+	fileMainTF = "main.tf.json"
+
+	errDecode         = "cannot decode manifest"
+	errNewObject      = "cannot create object of manifest"
+	errConvert        = "cannot convert manifest"
+	errNotTerraformed = "managed resource is not reconciled with Terraform"
+	errNoConfig       = "no configuration for Terraform resource"
+	errSetup          = "cannot build Terraform setup"
+	errTempDir        = "cannot create temporary workspace directory"
+	errFileProducer   = "cannot produce Terraform files"
+	errWriteMainTF    = "cannot write Terraform configuration"
+	errReadMainTF     = "cannot read Terraform configuration"
+	errMask           = "cannot mask sensitive Terraform arguments"
+	errGetSecret      = "cannot get referenced Secret"
+)
+
+// ReadObjects decodes the Kubernetes objects of the supplied YAML or JSON
+// streams, which may contain multiple documents, into the typed objects of the
+// supplied scheme.
+func ReadObjects(s *runtime.Scheme, streams ...io.Reader) ([]client.Object, error) {
+	var objs []client.Object
+	for _, r := range streams {
+		d := yaml.NewYAMLOrJSONDecoder(r, 4096)
+		for {
+			u := &unstructured.Unstructured{}
+			err := d.Decode(&u.Object)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, errDecode)
+			}
+			if len(u.Object) == 0 {
+				continue
+			}
+			o, err := s.New(u.GroupVersionKind())
+			if err != nil {
+				return nil, errors.Wrap(err, errNewObject)
+			}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, o); err != nil {
+				return nil, errors.Wrap(err, errConvert)
+			}
+			co, ok := o.(client.Object)
+			if !ok {
+				return nil, errors.New(errNewObject)
+			}
+			co.GetObjectKind().SetGroupVersionKind(u.GroupVersionKind())
+			if sec, ok := co.(*corev1.Secret); ok {
+				mergeStringData(sec)
+			}
+			objs = append(objs, co)
+		}
+	}
+	return objs, nil
+}
+
+// mergeStringData moves the string data of the supplied Secret into its data
+// like the API server does on write.
+func mergeStringData(s *corev1.Secret) {
+	if len(s.StringData) == 0 {
+		return
+	}
+	if s.Data == nil {
+		s.Data = map[string][]byte{}
+	}
+	for k, v := range s.StringData {
+		s.Data[k] = []byte(v)
+	}
+	s.StringData = nil
+}
+
+// A Workspace is the Terraform configuration of a managed resource.
+type Workspace struct {
+	// MainTF is the content of main.tf.json.
+	MainTF []byte
+
+	// Env is the environment Terraform is run with, on top of the one of the
+	// provider.
+	Env []string
+}
+
+// Render returns the Terraform workspace the provider would produce for the
+// supplied managed resource, resolving the ProviderConfig and Secrets it
+// references through the supplied client. Sensitive values are masked.
+func Render(ctx context.Context, kube client.Client, pc *tjconfig.Provider, setupFn terraform.SetupFn, mg client.Object) (*Workspace, error) {
+	tr, ok := mg.(resource.Terraformed)
+	if !ok {
+		return nil, errors.New(errNotTerraformed)
+	}
+	cfg, ok := pc.Resources[tr.GetTerraformResourceType()]
+	if !ok {
+		return nil, errors.Errorf("%s %s", errNoConfig, tr.GetTerraformResourceType())
+	}
+	ts, err := setupFn(ctx, kube, tr)
+	if err != nil {
+		return nil, errors.Wrap(err, errSetup)
+	}
+	dir, err := ioutil.TempDir("", "render")
+	if err != nil {
+		return nil, errors.Wrap(err, errTempDir)
+	}
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	fp, err := terraform.NewFileProducer(ctx, NewSecretClient(kube), dir, tr, ts, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, errFileProducer)
+	}
+	if err := fp.WriteMainTF(); err != nil {
+		return nil, errors.Wrap(err, errWriteMainTF)
+	}
+	raw, err := ioutil.ReadFile(filepath.Clean(filepath.Join(dir, fileMainTF)))
+	if err != nil {
+		return nil, errors.Wrap(err, errReadMainTF)
+	}
+	mainTF, err := MaskMainTF(raw, tr)
+	if err != nil {
+		return nil, errors.Wrap(err, errMask)
+	}
+	return &Workspace{MainTF: mainTF, Env: clients.MaskEnv(ts.Env, Masked)}, nil
+}
+
+// A secretClient reads the Secrets referenced by managed resources. Unlike
+// the one of the Terraform controllers it ignores missing Secrets, since the
+// connection Secret of a resource is usually not at hand locally.
+type secretClient struct {
+	kube client.Client
+}
+
+// NewSecretClient returns a client for the Secrets referenced by managed
+// resources that ignores missing connection Secrets.
+func NewSecretClient(kube client.Client) resource.SecretClient {
+	return &secretClient{kube: kube}
+}
+
+func (c *secretClient) GetSecretData(ctx context.Context, ref *xpv1.SecretReference) (map[string][]byte, error) {
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return nil, xpresource.IgnoreNotFound(err)
+	}
+	return s.Data, nil
+}
+
+func (c *secretClient) GetSecretValue(ctx context.Context, sel xpv1.SecretKeySelector) ([]byte, error) {
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: sel.Namespace, Name: sel.Name}, s); err != nil {
+		return nil, errors.Wrap(err, errGetSecret)
+	}
+	return s.Data[sel.Key], nil
+}
+
+// MaskAttributes masks the sensitive Terraform arguments or attributes of a
+// resource, i.e. the ones in its connection details mapping.
+func MaskAttributes(attrs map[string]interface{}, mapping map[string]string) error {
+	p := fieldpath.Pave(attrs)
+	for tf := range mapping {
+		paths, err := p.ExpandWildcards(tf)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if _, err := p.GetValue(path); fieldpath.IsNotFound(err) {
+				continue
+			}
+			if err := p.SetValue(path, Masked); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MaskMainTF masks the arguments of the resource block of the supplied
+// main.tf.json that are read from Secrets.
+func MaskMainTF(raw []byte, tr resource.Terraformed) ([]byte, error) {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	p := fieldpath.Pave(m)
+	block, err := p.GetValue(fieldpath.Segments{
+		fieldpath.Field("resource"),
+		fieldpath.Field(tr.GetTerraformResourceType()),
+		fieldpath.Field(tr.GetName()),
+	}.String())
+	if err != nil {
+		return nil, err
+	}
+	args, ok := block.(map[string]interface{})
+	if !ok {
+		return raw, nil
+	}
+	if err := MaskAttributes(args, tr.GetConnectionDetailsMapping()); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
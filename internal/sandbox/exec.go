@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"os"
+	osexec "os/exec"
+	"runtime"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	errNoCommand  = "no command to run in the sandbox"
+	errLookPath   = "cannot find command"
+	errSetRlimit  = "cannot set resource limit"
+	errRestrictFS = "cannot restrict the filesystem"
+	errExec       = "cannot run command"
+)
+
+// ExecOptions configure Exec.
+type ExecOptions struct {
+	// Writable are the paths that stay writable if ReadOnly is set.
+	Writable []string
+	// Limits are the resource limits of the command.
+	Limits Limits
+	// ReadOnly makes the filesystem apart from the Writable paths read-only
+	// where the kernel supports it.
+	ReadOnly bool
+}
+
+// Exec replaces the current process with the supplied command after applying
+// the resource limits and filesystem restrictions of the supplied options. It
+// only returns if it fails.
+func Exec(o ExecOptions, args []string) error {
+	if len(args) == 0 {
+		return errors.New(errNoCommand)
+	}
+	path, err := osexec.LookPath(args[0])
+	if err != nil {
+		return errors.Wrap(err, errLookPath)
+	}
+	limits := map[int]uint64{
+		syscall.RLIMIT_CPU:    o.Limits.CPUSeconds,
+		syscall.RLIMIT_AS:     o.Limits.AddressSpace,
+		syscall.RLIMIT_NOFILE: o.Limits.OpenFiles,
+	}
+	for r, v := range limits {
+		if v == 0 {
+			continue
+		}
+		if err := syscall.Setrlimit(r, &syscall.Rlimit{Cur: v, Max: v}); err != nil {
+			return errors.Wrap(err, errSetRlimit)
+		}
+	}
+	// Filesystem restrictions apply to the thread that sets them up, which
+	// therefore has to be the one that replaces the process.
+	runtime.LockOSThread()
+	if o.ReadOnly {
+		if err := restrictWrites(append(o.Writable, os.DevNull)); err != nil {
+			return errors.Wrap(err, errRestrictFS)
+		}
+	}
+	return errors.Wrap(syscall.Exec(path, args, os.Environ()), errExec)
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Landlock system calls and flags, see
+// https://docs.kernel.org/userspace-api/landlock.html
+const (
+	sysLandlockCreateRuleset = 444
+	sysLandlockAddRule       = 445
+	sysLandlockRestrictSelf  = 446
+
+	landlockRulePathBeneath = 1
+
+	accessWriteFile  = 1 << 1
+	accessRemoveDir  = 1 << 4
+	accessRemoveFile = 1 << 5
+	accessMakeChar   = 1 << 6
+	accessMakeDir    = 1 << 7
+	accessMakeReg    = 1 << 8
+	accessMakeSock   = 1 << 9
+	accessMakeFifo   = 1 << 10
+	accessMakeBlock  = 1 << 11
+	accessMakeSym    = 1 << 12
+
+	accessWrite = accessWriteFile | accessRemoveDir | accessRemoveFile | accessMakeChar | accessMakeDir |
+		accessMakeReg | accessMakeSock | accessMakeFifo | accessMakeBlock | accessMakeSym
+
+	oPath           = 0x200000
+	prSetNoNewPrivs = 38
+)
+
+type rulesetAttr struct {
+	handledAccessFS uint64
+}
+
+// pathBeneathAttr is packed by the kernel, which only reads its first 12
+// bytes. They have the same layout as the ones of this struct.
+type pathBeneathAttr struct {
+	allowedAccess uint64
+	parentFD      int32
+}
+
+// restrictWrites makes the filesystem apart from the supplied paths
+// read-only for the current thread and the processes it executes. Kernels
+// without Landlock are left unrestricted.
+func restrictWrites(writable []string) error {
+	attr := rulesetAttr{handledAccessFS: accessWrite}
+	fd, _, errno := syscall.Syscall(sysLandlockCreateRuleset, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno == syscall.ENOSYS || errno == syscall.EOPNOTSUPP {
+		return nil
+	}
+	if errno != 0 {
+		return errno
+	}
+	defer syscall.Close(int(fd)) // nolint:errcheck
+
+	for _, p := range writable {
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		pfd, err := syscall.Open(p, oPath|syscall.O_CLOEXEC, 0)
+		if err != nil {
+			return err
+		}
+		rule := pathBeneathAttr{allowedAccess: accessWrite, parentFD: int32(pfd)}
+		if !fi.IsDir() {
+			rule.allowedAccess = accessWriteFile
+		}
+		_, _, errno = syscall.Syscall6(sysLandlockAddRule, fd, landlockRulePathBeneath, uintptr(unsafe.Pointer(&rule)), 0, 0, 0)
+		syscall.Close(pfd) // nolint:errcheck
+		if errno != 0 {
+			return errno
+		}
+	}
+	if _, _, errno := syscall.Syscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+		return errno
+	}
+	if _, _, errno := syscall.Syscall(sysLandlockRestrictSelf, fd, 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sandbox runs Terraform with an allowlisted environment, a HOME,
+// plugin cache and temporary directory of its own in its workspace, a
+// filesystem that is read-only apart from its workspace and resource limits.
+package sandbox
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/utils/exec"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/procgroup"
+)
+
+const (
+	errNoDir    = "sandboxed command has no directory"
+	errMkdir    = "cannot create sandbox directory"
+	errExecFile = "cannot find the provider executable"
+	errNotStart = "sandboxed command has not been started"
+
+	// Command is the command of the provider executable that runs a
+	// command in the sandbox.
+	Command = "sandbox-exec"
+
+	dirHome        = ".home"
+	dirPluginCache = ".plugin-cache"
+	dirTmp         = ".tmp"
+
+	envHome        = "HOME"
+	envPluginCache = "TF_PLUGIN_CACHE_DIR"
+	envTmp         = "TMPDIR"
+)
+
+// DefaultEnv are the environment variables Terraform is run with by default.
+// A trailing * matches every variable with the preceding prefix.
+var DefaultEnv = []string{
+	"PATH",
+	"TF_CLI_CONFIG_FILE",
+	"TF_FORK",
+	"SSL_CERT_FILE",
+	"SSL_CERT_DIR",
+	"VAULT_*",
+	"TERRAFORM_VAULT_*",
+}
+
+// Limits are the resource limits of sandboxed commands. A limit that is 0 is
+// inherited from the provider.
+type Limits struct {
+	// CPUSeconds is the CPU time a command may use.
+	CPUSeconds uint64
+	// AddressSpace is the virtual memory, in bytes, a process may map.
+	AddressSpace uint64
+	// OpenFiles is the number of files a process may have open.
+	OpenFiles uint64
+}
+
+// Options configure the sandbox.
+type Options struct {
+	// Env are the environment variables commands are run with, see
+	// DefaultEnv.
+	Env []string
+	// Limits are the resource limits of commands.
+	Limits Limits
+	// ReadOnly makes the filesystem apart from the workspace read-only where
+	// the kernel supports it.
+	ReadOnly bool
+}
+
+// An Executor runs commands in the sandbox by running them through the
+// sandbox-exec command of the provider executable.
+type Executor struct {
+	exec.Interface
+	opts       Options
+	executable string
+}
+
+// NewExecutor returns an Executor with the supplied options.
+func NewExecutor(o Options) (*Executor, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, errors.Wrap(err, errExecFile)
+	}
+	return &Executor{Interface: procgroup.New(), opts: o, executable: self}, nil
+}
+
+// Command returns a sandboxed command.
+func (e *Executor) Command(cmd string, args ...string) exec.Cmd {
+	return e.CommandContext(context.Background(), cmd, args...)
+}
+
+// CommandContext returns a sandboxed command that is killed when the supplied
+// context is done.
+func (e *Executor) CommandContext(ctx context.Context, cmd string, args ...string) exec.Cmd {
+	return &command{executor: e, ctx: ctx, name: cmd, args: args, env: os.Environ()}
+}
+
+// Args returns the arguments of the sandbox-exec command that runs the
+// supplied command in the supplied workspace directory.
+func (e *Executor) Args(dir, cmd string, args ...string) []string {
+	a := []string{Command, "--writable=" + dir}
+	if l := e.opts.Limits; l.CPUSeconds > 0 {
+		a = append(a, "--cpu-seconds="+strconv.FormatUint(l.CPUSeconds, 10))
+	}
+	if l := e.opts.Limits; l.AddressSpace > 0 {
+		a = append(a, "--address-space="+strconv.FormatUint(l.AddressSpace, 10))
+	}
+	if l := e.opts.Limits; l.OpenFiles > 0 {
+		a = append(a, "--open-files="+strconv.FormatUint(l.OpenFiles, 10))
+	}
+	if e.opts.ReadOnly {
+		a = append(a, "--read-only")
+	}
+	return append(append(a, "--", cmd), args...)
+}
+
+// Env returns the allowlisted variables of the supplied environment, and the
+// HOME, plugin cache and temporary directory of the supplied workspace.
+func (e *Executor) Env(dir string, env []string) []string {
+	result := make([]string, 0, len(env)+3)
+	for _, kv := range env {
+		name := strings.SplitN(kv, "=", 2)[0]
+		switch name {
+		case envHome, envPluginCache, envTmp:
+			continue
+		}
+		if allowed(e.opts.Env, name) {
+			result = append(result, kv)
+		}
+	}
+	return append(result,
+		envHome+"="+filepath.Join(dir, dirHome),
+		envPluginCache+"="+filepath.Join(dir, dirPluginCache),
+		envTmp+"="+filepath.Join(dir, dirTmp),
+	)
+}
+
+func allowed(allowlist []string, name string) bool {
+	for _, a := range allowlist {
+		if strings.HasSuffix(a, "*") && strings.HasPrefix(name, strings.TrimSuffix(a, "*")) {
+			return true
+		}
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// command is a command that is only created once it is run, when its
+// directory is known.
+type command struct {
+	executor *Executor
+	ctx      context.Context
+	name     string
+	args     []string
+
+	dir    string
+	env    []string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	cmd exec.Cmd
+}
+
+func (c *command) SetDir(dir string) {
+	c.dir = dir
+}
+
+func (c *command) SetStdin(in io.Reader) {
+	c.stdin = in
+}
+
+func (c *command) SetStdout(out io.Writer) {
+	c.stdout = out
+}
+
+func (c *command) SetStderr(out io.Writer) {
+	c.stderr = out
+}
+
+func (c *command) SetEnv(env []string) {
+	c.env = env
+}
+
+// StdoutPipe creates the command, so its directory has to be set before.
+func (c *command) StdoutPipe() (io.ReadCloser, error) {
+	if err := c.build(); err != nil {
+		return nil, err
+	}
+	return c.cmd.StdoutPipe()
+}
+
+// StderrPipe creates the command, so its directory has to be set before.
+func (c *command) StderrPipe() (io.ReadCloser, error) {
+	if err := c.build(); err != nil {
+		return nil, err
+	}
+	return c.cmd.StderrPipe()
+}
+
+func (c *command) Stop() {
+	if c.cmd != nil {
+		c.cmd.Stop()
+	}
+}
+
+// build creates the command that runs the sandbox-exec command.
+func (c *command) build() error {
+	if c.cmd != nil {
+		return nil
+	}
+	if c.dir == "" {
+		return errors.New(errNoDir)
+	}
+	for _, d := range []string{dirHome, dirPluginCache, dirTmp} {
+		if err := os.MkdirAll(filepath.Join(c.dir, d), 0700); err != nil {
+			return errors.Wrap(err, errMkdir)
+		}
+	}
+	e := c.executor
+	c.cmd = e.Interface.CommandContext(c.ctx, e.executable, e.Args(c.dir, c.name, c.args...)...)
+	c.cmd.SetDir(c.dir)
+	c.cmd.SetEnv(e.Env(c.dir, c.env))
+	if c.stdin != nil {
+		c.cmd.SetStdin(c.stdin)
+	}
+	if c.stdout != nil {
+		c.cmd.SetStdout(c.stdout)
+	}
+	if c.stderr != nil {
+		c.cmd.SetStderr(c.stderr)
+	}
+	return nil
+}
+
+func (c *command) Run() error {
+	if err := c.build(); err != nil {
+		return err
+	}
+	return c.cmd.Run()
+}
+
+func (c *command) CombinedOutput() ([]byte, error) {
+	if err := c.build(); err != nil {
+		return nil, err
+	}
+	return c.cmd.CombinedOutput()
+}
+
+func (c *command) Output() ([]byte, error) {
+	if err := c.build(); err != nil {
+		return nil, err
+	}
+	return c.cmd.Output()
+}
+
+func (c *command) Start() error {
+	if err := c.build(); err != nil {
+		return err
+	}
+	return c.cmd.Start()
+}
+
+func (c *command) Wait() error {
+	if c.cmd == nil {
+		return errors.New(errNotStart)
+	}
+	return c.cmd.Wait()
+}
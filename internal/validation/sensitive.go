@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation contains the checks that are run on the output of the
+// code generation pipeline.
+package validation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	tjconfig "github.com/crossplane/terrajet/pkg/config"
+	"github.com/crossplane/terrajet/pkg/types/name"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	wildcard = "*"
+
+	prefixSpec   = "spec.forProvider."
+	prefixStatus = "status.atProvider."
+	suffixRef    = "SecretRef"
+
+	fnConnectionDetailsMapping = "GetConnectionDetailsMapping"
+
+	errParseFile = "cannot parse generated file %s"
+)
+
+// A Violation is a sensitive Terraform attribute that is not handled as one
+// by the generated API.
+type Violation struct {
+	// Resource is the name of the Terraform resource, e.g. vault_generic_secret.
+	Resource string
+	// Attribute is the path of the attribute in the Terraform schema.
+	Attribute string
+	// Reason describes how the attribute is exposed.
+	Reason string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.Resource, v.Attribute, v.Reason)
+}
+
+// A Report lists the violations found during validation. It satisfies the
+// error interface so that it can be returned as is.
+type Report []Violation
+
+func (r Report) Error() string {
+	lines := make([]string, 0, len(r)+1)
+	lines = append(lines, fmt.Sprintf("%d sensitive attribute(s) are exposed as plain fields:", len(r)))
+	for _, v := range r {
+		lines = append(lines, "  "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SensitiveFields checks the API types generated under the supplied root
+// directory for the resources of the supplied provider configuration. Every
+// sensitive attribute of their Terraform schemas has to be a Secret reference
+// in spec if it is configurable, or a connection detail if it is computed, and
+// must never be a plain spec or status field. A Report is returned if any of
+// them is not.
+func SensitiveFields(pc *tjconfig.Provider, rootDir string) error {
+	names := make([]string, 0, len(pc.Resources))
+	for n := range pc.Resources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var report Report
+	for _, n := range names {
+		r := pc.Resources[n]
+		attrs := sensitiveAttributes(r.TerraformResource, nil)
+		if len(attrs) == 0 {
+			continue
+		}
+		dir := apiDirectory(pc, r, rootDir)
+		structs, err := parseStructs(filepath.Join(dir, fmt.Sprintf("zz_%s_types.go", strings.ToLower(r.Kind))))
+		if err != nil {
+			return err
+		}
+		mapping, err := parseConnectionDetailsMapping(filepath.Join(dir, fmt.Sprintf("zz_%s_terraformed.go", strings.ToLower(r.Kind))))
+		if err != nil {
+			return err
+		}
+		for _, a := range attrs {
+			for _, reason := range check(r.Kind, a, structs, mapping) {
+				report = append(report, Violation{Resource: n, Attribute: a.String(), Reason: reason})
+			}
+		}
+	}
+	if len(report) > 0 {
+		return report
+	}
+	return nil
+}
+
+// apiDirectory returns the directory Terrajet generates the API types of the
+// supplied resource into.
+func apiDirectory(pc *tjconfig.Provider, r *tjconfig.Resource, rootDir string) string {
+	group := pc.RootGroup
+	if r.ShortGroup != "" {
+		group = strings.ToLower(r.ShortGroup) + "." + pc.RootGroup
+	}
+	return filepath.Join(rootDir, "apis", strings.ToLower(strings.Split(group, ".")[0]), r.Version)
+}
+
+type attribute struct {
+	path   []string
+	schema *schema.Schema
+}
+
+// String returns the path of the attribute in the format Terrajet uses for
+// the keys of connection details mappings, e.g. block[*].field.
+func (a attribute) String() string {
+	seg := make(fieldpath.Segments, len(a.path))
+	for i, p := range a.path {
+		seg[i] = fieldpath.Field(p)
+	}
+	return seg.String()
+}
+
+func (a attribute) observation() bool {
+	return a.schema.Computed && !a.schema.Optional
+}
+
+// sensitiveAttributes returns the sensitive attributes of the supplied
+// Terraform resource schema, including the ones of nested blocks.
+func sensitiveAttributes(res *schema.Resource, path []string) []attribute {
+	if res == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(res.Schema))
+	for k := range res.Schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var result []attribute
+	for _, k := range keys {
+		sch := res.Schema[k]
+		p := append(append([]string{}, path...), k)
+		if sch.Sensitive {
+			result = append(result, attribute{path: p, schema: sch})
+		}
+		if elem, ok := sch.Elem.(*schema.Resource); ok {
+			result = append(result, sensitiveAttributes(elem, append(p, wildcard))...)
+		}
+	}
+	return result
+}
+
+// check returns the reasons the supplied sensitive attribute is not handled
+// as one by the generated types and connection details mapping of a kind.
+func check(kind string, a attribute, structs map[string]*ast.StructType, mapping map[string]string) []string {
+	var reasons []string
+	last := a.path[len(a.path)-1]
+	target := mapping[a.String()]
+
+	// A sensitive attribute must never be a field that is serialized to or
+	// from its Terraform counterpart, wherever Terrajet placed it.
+	if f := lookup(structs, kind+"Parameters", a.path); f != nil && tfName(f) == last {
+		reasons = append(reasons, fmt.Sprintf("exposed as plain spec field %s", f.Names[0].Name))
+	}
+	if f := lookup(structs, kind+"Observation", a.path); f != nil && tfName(f) == last {
+		reasons = append(reasons, fmt.Sprintf("exposed as plain status field %s", f.Names[0].Name))
+	}
+
+	if a.observation() {
+		if !strings.HasPrefix(target, prefixStatus) {
+			reasons = append(reasons, "computed but not mapped to a connection detail")
+		}
+		return reasons
+	}
+	if !strings.HasPrefix(target, prefixSpec) || !strings.HasSuffix(target, suffixRef) {
+		reasons = append(reasons, "configurable but not mapped to a Secret reference")
+	}
+	ref := append(append([]string{}, a.path[:len(a.path)-1]...), name.NewFromSnake(last).LowerCamelComputed+suffixRef)
+	if f := lookupJSON(structs, kind+"Parameters", ref); f == nil || !isSecretRef(f) {
+		reasons = append(reasons, "configurable but has no Secret reference field")
+	}
+	return reasons
+}
+
+// lookup returns the field of the struct with the supplied name at the
+// supplied Terraform path, if there is one.
+func lookup(structs map[string]*ast.StructType, root string, path []string) *ast.Field {
+	return walk(structs, root, path, func(f *ast.Field, seg string, _ bool) bool { return tfName(f) == seg })
+}
+
+// lookupJSON is like lookup but matches the last segment of the path against
+// the JSON name of the field, which is the only name Secret references have.
+func lookupJSON(structs map[string]*ast.StructType, root string, path []string) *ast.Field {
+	return walk(structs, root, path, func(f *ast.Field, seg string, last bool) bool {
+		if last {
+			return tagName(f, "json") == seg
+		}
+		return tfName(f) == seg
+	})
+}
+
+func walk(structs map[string]*ast.StructType, root string, path []string, match func(f *ast.Field, seg string, last bool) bool) *ast.Field {
+	st := structs[root]
+	var segs []string
+	for _, p := range path {
+		if p != wildcard {
+			segs = append(segs, p)
+		}
+	}
+	for i, seg := range segs {
+		if st == nil {
+			return nil
+		}
+		var found *ast.Field
+		for _, f := range st.Fields.List {
+			if len(f.Names) == 1 && match(f, seg, i == len(segs)-1) {
+				found = f
+				break
+			}
+		}
+		if found == nil || i == len(segs)-1 {
+			return found
+		}
+		st = structs[typeName(found.Type)]
+	}
+	return nil
+}
+
+// typeName returns the name of the underlying named type of pointers, slices
+// and maps.
+func typeName(e ast.Expr) string {
+	for {
+		switch t := e.(type) {
+		case *ast.StarExpr:
+			e = t.X
+		case *ast.ArrayType:
+			e = t.Elt
+		case *ast.MapType:
+			e = t.Value
+		default:
+			return types.ExprString(e)
+		}
+	}
+}
+
+func isSecretRef(f *ast.Field) bool {
+	return tfName(f) == "-" && strings.HasSuffix(typeName(f.Type), "SecretKeySelector")
+}
+
+func tfName(f *ast.Field) string {
+	return tagName(f, "tf")
+}
+
+func tagName(f *ast.Field, key string) string {
+	if f.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return strings.Split(reflect.StructTag(tag).Get(key), ",")[0]
+}
+
+// parseStructs returns the struct types declared in the supplied file by
+// their names.
+func parseStructs(path string) (map[string]*ast.StructType, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, errParseFile, path)
+	}
+	structs := map[string]*ast.StructType{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ts, ok := n.(*ast.TypeSpec); ok {
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				structs[ts.Name.Name] = st
+			}
+		}
+		return true
+	})
+	return structs, nil
+}
+
+// parseConnectionDetailsMapping returns the map literal returned by the
+// GetConnectionDetailsMapping function declared in the supplied file.
+func parseConnectionDetailsMapping(path string) (map[string]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, errParseFile, path)
+	}
+	mapping := map[string]string{}
+	for _, d := range f.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != fnConnectionDetailsMapping {
+			continue
+		}
+		ast.Inspect(fn, func(n ast.Node) bool {
+			kv, ok := n.(*ast.KeyValueExpr)
+			if !ok {
+				return true
+			}
+			k, kok := kv.Key.(*ast.BasicLit)
+			v, vok := kv.Value.(*ast.BasicLit)
+			if !kok || !vok {
+				return true
+			}
+			ks, kerr := strconv.Unquote(k.Value)
+			vs, verr := strconv.Unquote(v.Value)
+			if kerr == nil && verr == nil {
+				mapping[ks] = vs
+			}
+			return true
+		})
+	}
+	return mapping, nil
+}
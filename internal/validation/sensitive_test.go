@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tjconfig "github.com/crossplane/terrajet/pkg/config"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// fixtureSchema is the schema of the resources of the fixture package under
+// testdata, which handles its sensitive attributes as Good does and exposes
+// them as Bad does.
+var fixtureSchema = &schema.Resource{
+	Schema: map[string]*schema.Schema{
+		"path":      {Type: schema.TypeString, Required: true},
+		"data_json": {Type: schema.TypeString, Required: true, Sensitive: true},
+		"token":     {Type: schema.TypeString, Computed: true, Sensitive: true},
+		"auth": {
+			Type:     schema.TypeList,
+			Optional: true,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"username": {Type: schema.TypeString, Required: true},
+					"password": {Type: schema.TypeString, Optional: true, Sensitive: true},
+				},
+			},
+		},
+	},
+}
+
+func fixture(kinds ...string) *tjconfig.Provider {
+	pc := &tjconfig.Provider{RootGroup: "vault.jet.crossplane.io", Resources: map[string]*tjconfig.Resource{}}
+	for _, k := range kinds {
+		n := "vault_" + strings.ToLower(k)
+		pc.Resources[n] = &tjconfig.Resource{
+			Name:              n,
+			TerraformResource: fixtureSchema,
+			ShortGroup:        "test",
+			Version:           "v1alpha1",
+			Kind:              k,
+		}
+	}
+	return pc
+}
+
+func TestSensitiveFields(t *testing.T) {
+	cases := map[string]struct {
+		pc      *tjconfig.Provider
+		want    Report
+		wantErr bool
+	}{
+		"Handled": {
+			pc: fixture("Good"),
+		},
+		"Exposed": {
+			pc: fixture("Bad", "Good"),
+			want: Report{
+				{Resource: "vault_bad", Attribute: "auth[*].password", Reason: "exposed as plain spec field Password"},
+				{Resource: "vault_bad", Attribute: "auth[*].password", Reason: "configurable but not mapped to a Secret reference"},
+				{Resource: "vault_bad", Attribute: "auth[*].password", Reason: "configurable but has no Secret reference field"},
+				{Resource: "vault_bad", Attribute: "data_json", Reason: "exposed as plain spec field DataJSON"},
+				{Resource: "vault_bad", Attribute: "data_json", Reason: "configurable but not mapped to a Secret reference"},
+				{Resource: "vault_bad", Attribute: "data_json", Reason: "configurable but has no Secret reference field"},
+				{Resource: "vault_bad", Attribute: "token", Reason: "exposed as plain status field Token"},
+				{Resource: "vault_bad", Attribute: "token", Reason: "computed but not mapped to a connection detail"},
+			},
+		},
+		"NotGenerated": {
+			pc:      fixture("Missing"),
+			wantErr: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := SensitiveFields(tc.pc, "testdata")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("SensitiveFields(...): no error, want one")
+				}
+				return
+			}
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("SensitiveFields(...): %v", err)
+				}
+				return
+			}
+			if got, ok := err.(Report); !ok || !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SensitiveFields(...): %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/terrajet/pkg/config"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/resource/json"
+	"github.com/crossplane/terrajet/pkg/terraform"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-jet-vault/apis/v1alpha1"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/clients"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/poll"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/render"
+)
+
+// PrefixBatch prefixes the names of the directories of batched workspaces.
+const PrefixBatch = "batch-"
+
+const (
+	fileMainTF  = "main.tf.json"
+	fileTFState = "terraform.tfstate"
+
+	errSetup        = "cannot get Terraform setup"
+	errTempDir      = "cannot create temporary directory"
+	errMkdir        = "cannot create directory for batched workspace"
+	errReadFile     = "cannot read workspace file"
+	errWriteFile    = "cannot write workspace file"
+	errUnmarshal    = "cannot unmarshal workspace file"
+	errMarshal      = "cannot marshal workspace file"
+	errInit         = "cannot init batched workspace"
+	errRefresh      = "cannot refresh batched workspace"
+	errPlan         = "cannot plan batched workspace"
+	errProduceFiles = "cannot produce the workspace files of managed resource"
+)
+
+// A BatchResult is the observation of a managed resource made in a batched
+// workspace.
+type BatchResult struct {
+	// Generation is the generation of the managed resource the observation
+	// was made for.
+	Generation int64
+	// SecretVersions are the resource versions of the ProviderConfig and of
+	// the Secrets the observation was made with, see secretVersions.
+	SecretVersions string
+	// Time is the time the observation was started at.
+	Time time.Time
+	// Exists is true if the external resource exists.
+	Exists bool
+	// Attributes are the refreshed Terraform state attributes.
+	Attributes []byte
+	// PrivateRaw is the private Terraform state of the resource.
+	PrivateRaw []byte
+	// UpToDate is true if Terraform planned no changes to the resource.
+	UpToDate bool
+}
+
+type member struct {
+	object resource.Terraformed
+	config *config.Resource
+}
+
+// A batchKey identifies the managed resources observed in the same batched
+// workspace.
+type batchKey struct {
+	providerConfig string
+	kind           string
+	resourceType   string
+}
+
+func (k batchKey) id() string {
+	sum := sha256.Sum256([]byte(k.providerConfig + "/" + k.resourceType))
+	return PrefixBatch + hex.EncodeToString(sum[:8])
+}
+
+// A Batcher periodically observes the Terraform resources that share a
+// ProviderConfig and a kind in one workspace, so that Terraform, the provider
+// plugin and the Vault login run once per group instead of once per resource.
+// Writes are never batched.
+type Batcher struct {
+	store    *Store
+	kube     client.Client
+	setupFn  terraform.SetupFn
+	interval time.Duration
+	log      logging.Logger
+
+	mu          sync.Mutex
+	members     map[types.UID]member
+	results     map[types.UID]BatchResult
+	invalidated map[types.UID]time.Time
+}
+
+// EnableBatching makes the Store observe its resources in batched workspaces
+// at the supplied interval. The returned Batcher has to be started for the
+// observations to run.
+func (s *Store) EnableBatching(kube client.Client, setupFn terraform.SetupFn, interval time.Duration, l logging.Logger) *Batcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batcher = &Batcher{
+		store:       s,
+		kube:        kube,
+		setupFn:     setupFn,
+		interval:    interval,
+		log:         l.WithValues("interval", interval.String()),
+		members:     map[types.UID]member{},
+		results:     map[types.UID]BatchResult{},
+		invalidated: map[types.UID]time.Time{},
+	}
+	return s.batcher
+}
+
+// Batcher returns the Batcher of the Store, or nil if batching is not
+// enabled.
+func (s *Store) Batcher() *Batcher {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.batcher
+}
+
+// Register adds the supplied managed resource to the next batched
+// observations, or updates it if it is already added.
+func (b *Batcher) Register(tr resource.Terraformed, cfg *config.Resource) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.members[tr.GetUID()] = member{object: tr.DeepCopyObject().(resource.Terraformed), config: cfg}
+}
+
+// Forget removes the supplied managed resource from the batched observations.
+func (b *Batcher) Forget(uid types.UID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.members, uid)
+	delete(b.results, uid)
+	delete(b.invalidated, uid)
+}
+
+// Invalidate drops the last batched observation of the supplied managed
+// resource, e.g. because it is being written to, along with any observation
+// that is in progress.
+func (b *Batcher) Invalidate(uid types.UID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.results, uid)
+	b.invalidated[uid] = time.Now()
+}
+
+// Result returns the last batched observation of the supplied managed
+// resource if it was made for its current generation and with the current
+// versions of its ProviderConfig and of the Secrets it references, and is not
+// older than two intervals.
+func (b *Batcher) Result(ctx context.Context, tr resource.Terraformed) (BatchResult, bool) {
+	b.mu.Lock()
+	r, ok := b.results[tr.GetUID()]
+	b.mu.Unlock()
+	if !ok || r.Generation != tr.GetGeneration() || time.Since(r.Time) > 2*b.interval {
+		return BatchResult{}, false
+	}
+	if r.SecretVersions != secretVersions(ctx, b.kube, tr) {
+		return BatchResult{}, false
+	}
+	return r, true
+}
+
+// secretVersions returns the resource versions of the ProviderConfig of the
+// supplied resource, of its credentials Secret and of the Secrets the
+// resource references. They change what Terraform observes without changing
+// the generation of the resource. Objects that cannot be read have no
+// version.
+func secretVersions(ctx context.Context, kube client.Client, tr resource.Terraformed) string {
+	var versions []string
+	refs := poll.SecretRefs(tr)
+	if ref := tr.GetProviderConfigReference(); ref != nil {
+		pc := &v1alpha1.ProviderConfig{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err == nil {
+			versions = append(versions, "providerconfig/"+pc.GetName()+"="+pc.GetResourceVersion())
+			if s := pc.Spec.Credentials.SecretRef; s != nil {
+				refs = append(refs, types.NamespacedName{Namespace: s.Namespace, Name: s.Name})
+			}
+		}
+	}
+	for _, nn := range refs {
+		s := &corev1.Secret{}
+		if err := clients.SecretReader(kube).Get(ctx, nn, s); err != nil {
+			s = &corev1.Secret{}
+		}
+		versions = append(versions, nn.String()+"="+s.GetResourceVersion())
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ",")
+}
+
+// Start observes the registered resources at every interval until the
+// supplied context is done.
+func (b *Batcher) Start(ctx context.Context) error {
+	t := time.NewTicker(b.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			b.observe(ctx)
+		}
+	}
+}
+
+func (b *Batcher) observe(ctx context.Context) {
+	b.mu.Lock()
+	groups := map[batchKey][]member{}
+	for _, m := range b.members {
+		k := batchKey{kind: kindOf(m.object), resourceType: m.object.GetTerraformResourceType()}
+		if ref := m.object.GetProviderConfigReference(); ref != nil {
+			k.providerConfig = ref.Name
+		}
+		groups[k] = append(groups[k], m)
+	}
+	b.mu.Unlock()
+
+	for k, ms := range groups {
+		sort.Slice(ms, func(i, j int) bool { return ms[i].object.GetName() < ms[j].object.GetName() })
+		gctx, cancel := context.WithTimeout(ctx, b.interval)
+		results, err := b.observeGroup(gctx, k, ms)
+		cancel()
+		if err != nil {
+			// The members of the group are observed in their own workspaces
+			// until the next batched observation succeeds.
+			b.log.Debug("Cannot observe batched workspace", "kind", k.kind, "providerConfig", k.providerConfig, "error", err)
+		}
+		b.mu.Lock()
+		for _, m := range ms {
+			uid := m.object.GetUID()
+			r, ok := results[uid]
+			switch {
+			case ok && r.Time.After(b.invalidated[uid]):
+				b.results[uid] = r
+			default:
+				delete(b.results, uid)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
+
+// observeGroup refreshes and plans the supplied members of a group in one
+// workspace and returns their observations. Members whose workspace files
+// cannot be produced are left out so that they do not block the others.
+func (b *Batcher) observeGroup(ctx context.Context, k batchKey, ms []member) (map[types.UID]BatchResult, error) { // nolint:gocyclo
+	start := time.Now()
+	ts, err := b.setupFn(ctx, b.kube, ms[0].object)
+	if err != nil {
+		return nil, errors.Wrap(err, errSetup)
+	}
+	scratch, err := ioutil.TempDir("", PrefixBatch)
+	if err != nil {
+		return nil, errors.Wrap(err, errTempDir)
+	}
+	defer os.RemoveAll(scratch) // nolint:errcheck
+
+	mainTF := map[string]interface{}{}
+	blocks := map[string]map[string]interface{}{}
+	state := json.NewStateV4()
+	state.TerraformVersion = ts.Version
+	state.Lineage = k.id()
+	var included []member
+	versions := make(map[types.UID]string, len(ms))
+	for _, m := range ms {
+		// The versions are taken before the Secrets are read so that a
+		// change in between invalidates the observation.
+		versions[m.object.GetUID()] = secretVersions(ctx, b.kube, m.object)
+		main, st, err := produce(ctx, b.kube, scratch, m, ts)
+		if err != nil {
+			b.log.Debug(errProduceFiles, "name", m.object.GetName(), "error", err)
+			continue
+		}
+		mainTF["terraform"], mainTF["provider"] = main["terraform"], main["provider"]
+		for t, rs := range main["resource"].(map[string]interface{}) {
+			if blocks[t] == nil {
+				blocks[t] = map[string]interface{}{}
+			}
+			for n, r := range rs.(map[string]interface{}) {
+				blocks[t][n] = r
+			}
+		}
+		state.Resources = append(state.Resources, st.Resources...)
+		included = append(included, m)
+	}
+	if len(included) == 0 {
+		return nil, nil
+	}
+	mainTF["resource"] = blocks
+
+	dir := filepath.Join(os.TempDir(), k.id())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, errMkdir)
+	}
+	if err := writeJSON(filepath.Join(dir, fileMainTF), mainTF); err != nil {
+		return nil, err
+	}
+	if err := writeJSON(filepath.Join(dir, fileTFState), state); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(included))
+	for i, m := range included {
+		names[i] = m.object.GetName()
+	}
+	e := b.store.batchEntry(k, dir, names)
+	run := func(args ...string) ([]byte, error) {
+		cmd := b.store.executorOf(e).CommandContext(ctx, "terraform", args...)
+		cmd.SetEnv(append(os.Environ(), ts.Env...))
+		cmd.SetDir(dir)
+		return cmd.CombinedOutput()
+	}
+	if _, err := os.Stat(filepath.Join(dir, fileLock)); os.IsNotExist(err) {
+		if out, err := run("init", "-input=false"); err != nil {
+			return nil, errors.Wrapf(err, "%s: %s", errInit, out)
+		}
+	}
+	if out, err := run("apply", "-refresh-only", "-auto-approve", "-input=false", "-lock=false", "-json"); err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", errRefresh, out)
+	}
+	refreshed := &json.StateV4{}
+	if err := readJSON(filepath.Join(dir, fileTFState), refreshed); err != nil {
+		return nil, err
+	}
+	out, err := run("plan", "-refresh=false", "-input=false", "-lock=false", "-json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: %s", errPlan, out)
+	}
+	changed := plannedChanges(out)
+
+	results := make(map[types.UID]BatchResult, len(included))
+	for _, m := range included {
+		r := BatchResult{Generation: m.object.GetGeneration(), SecretVersions: versions[m.object.GetUID()], Time: start}
+		addr := m.object.GetTerraformResourceType() + "." + m.object.GetName()
+		for _, rs := range refreshed.Resources {
+			if rs.Type+"."+rs.Name != addr || len(rs.Instances) == 0 {
+				continue
+			}
+			r.Exists = rs.Instances[0].AttributesRaw != nil
+			r.Attributes = rs.Instances[0].AttributesRaw
+			r.PrivateRaw = rs.Instances[0].PrivateRaw
+		}
+		r.UpToDate = !changed[addr]
+		results[m.object.GetUID()] = r
+	}
+	return results, nil
+}
+
+// produce returns the main configuration and the state of the supplied member
+// as Terrajet would write them into its own workspace.
+func produce(ctx context.Context, kube client.Client, scratch string, m member, ts terraform.Setup) (map[string]interface{}, *json.StateV4, error) {
+	dir, err := ioutil.TempDir(scratch, "")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errTempDir)
+	}
+	fp, err := terraform.NewFileProducer(ctx, render.NewSecretClient(kube), dir, m.object, ts, m.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := fp.WriteMainTF(); err != nil {
+		return nil, nil, err
+	}
+	if err := fp.WriteTFState(ctx); err != nil {
+		return nil, nil, err
+	}
+	main := map[string]interface{}{}
+	if err := readJSON(filepath.Join(dir, fileMainTF), &main); err != nil {
+		return nil, nil, err
+	}
+	st := &json.StateV4{}
+	if err := readJSON(filepath.Join(dir, fileTFState), st); err != nil {
+		return nil, nil, err
+	}
+	return main, st, nil
+}
+
+// plannedChanges returns the addresses of the resources the supplied machine
+// readable plan output has changes for.
+func plannedChanges(out []byte) map[string]bool {
+	type line struct {
+		Type   string `json:"type"`
+		Change struct {
+			Resource struct {
+				Addr string `json:"addr"`
+			} `json:"resource"`
+			Action string `json:"action"`
+		} `json:"change"`
+	}
+	changed := map[string]bool{}
+	s := bufio.NewScanner(bytes.NewReader(out))
+	s.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for s.Scan() {
+		l := line{}
+		if err := json.JSParser.Unmarshal(s.Bytes(), &l); err != nil || l.Type != "planned_change" {
+			continue
+		}
+		if l.Change.Action != "noop" && l.Change.Action != "read" {
+			changed[l.Change.Resource.Addr] = true
+		}
+	}
+	return changed
+}
+
+func readJSON(path string, v interface{}) error {
+	raw, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return errors.Wrap(err, errReadFile)
+	}
+	return errors.Wrap(json.JSParser.Unmarshal(raw, v), errUnmarshal)
+}
+
+func writeJSON(path string, v interface{}) error {
+	raw, err := json.JSParser.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, errMarshal)
+	}
+	return errors.Wrap(ioutil.WriteFile(path, raw, 0600), errWriteFile)
+}
+
+// batchEntry returns the entry of the batched workspace of the supplied group
+// with the supplied members.
+func (s *Store) batchEntry(k batchKey, dir string, members []string) *entry {
+	uid := types.UID(k.id())
+	s.mu.Lock()
+	e, ok := s.entries[uid]
+	if !ok {
+		e = &entry{
+			status: Status{
+				UID:            uid,
+				Kind:           k.kind,
+				ProviderConfig: k.providerConfig,
+				Directory:      dir,
+			},
+			running: map[*Operation]struct{}{},
+		}
+		s.entries[uid] = e
+	}
+	s.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.Members = members
+	return e
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"context"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/config"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/resource/json"
+	"github.com/pkg/errors"
+)
+
+const (
+	errNotTerraformed         = "managed resource is not a Terraformed resource"
+	errUnmarshalAttributes    = "cannot unmarshal state attributes"
+	errSetObservation         = "cannot set observation"
+	errSetCriticalAnnotations = "cannot set critical annotations"
+	errGetConnectionDetails   = "cannot get connection details"
+	errLateInitialize         = "cannot late initialize parameters"
+)
+
+// NewBatchConnector returns an ExternalConnecter that observes the managed
+// resources of the supplied Terraform resource with the batched observations
+// of the Store, and with the supplied ExternalConnecter when there is none.
+// The supplied ExternalConnecter is returned as is if batching is not enabled
+// or the resource is operated on asynchronously.
+func NewBatchConnector(c managed.ExternalConnecter, s *Store, cfg *config.Resource) managed.ExternalConnecter {
+	b := s.Batcher()
+	if b == nil || cfg.UseAsync {
+		return c
+	}
+	return &batchConnector{wrapped: c, batcher: b, config: cfg}
+}
+
+type batchConnector struct {
+	wrapped managed.ExternalConnecter
+	batcher *Batcher
+	config  *config.Resource
+}
+
+// Connect defers connecting with the wrapped ExternalConnecter, which sets up
+// the Terraform workspace of the resource, until it is needed.
+func (c *batchConnector) Connect(_ context.Context, mg xpresource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(resource.Terraformed); !ok {
+		return nil, errors.New(errNotTerraformed)
+	}
+	return &batchExternal{batchConnector: c}, nil
+}
+
+type batchExternal struct {
+	*batchConnector
+	client managed.ExternalClient
+}
+
+func (e *batchExternal) connect(ctx context.Context, mg xpresource.Managed) (managed.ExternalClient, error) {
+	if e.client != nil {
+		return e.client, nil
+	}
+	ec, err := e.wrapped.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	e.client = ec
+	return ec, nil
+}
+
+// Observe demultiplexes the last batched observation of the resource the
+// same way Terrajet consumes the observation of its own workspace.
+func (e *batchExternal) Observe(ctx context.Context, mg xpresource.Managed) (managed.ExternalObservation, error) {
+	tr := mg.(resource.Terraformed)
+	if meta.WasDeleted(tr) {
+		e.batcher.Forget(tr.GetUID())
+		return e.observe(ctx, tr)
+	}
+	// Resources join the batched observations only once they are created.
+	if meta.GetExternalName(tr) == "" {
+		return e.observe(ctx, tr)
+	}
+	e.batcher.Register(tr, e.config)
+	r, ok := e.batcher.Result(ctx, tr)
+	if !ok || !r.Exists {
+		// Only the workspace of the resource can tell that it has to be
+		// created.
+		return e.observe(ctx, tr)
+	}
+
+	tfstate := map[string]interface{}{}
+	if err := json.JSParser.Unmarshal(r.Attributes, &tfstate); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errUnmarshalAttributes)
+	}
+	if err := tr.SetObservation(tfstate); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSetObservation)
+	}
+	annotationsUpdated, err := resource.SetCriticalAnnotations(tr, e.config, tfstate, string(r.PrivateRaw))
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errSetCriticalAnnotations)
+	}
+	conn, err := resource.GetConnectionDetails(tfstate, tr, e.config)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetConnectionDetails)
+	}
+	if !tr.GetCondition(xpv1.TypeReady).Equal(xpv1.Available()) {
+		tr.SetConditions(xpv1.Available())
+		return managed.ExternalObservation{
+			ResourceExists:          true,
+			ResourceUpToDate:        true,
+			ConnectionDetails:       conn,
+			ResourceLateInitialized: annotationsUpdated,
+		}, nil
+	}
+	lateInitialized, err := tr.LateInitialize(r.Attributes)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errLateInitialize)
+	}
+	return managed.ExternalObservation{
+		ResourceExists:          true,
+		ResourceUpToDate:        r.UpToDate,
+		ResourceLateInitialized: annotationsUpdated || lateInitialized,
+		ConnectionDetails:       conn,
+	}, nil
+}
+
+func (e *batchExternal) observe(ctx context.Context, mg xpresource.Managed) (managed.ExternalObservation, error) {
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	return ec.Observe(ctx, mg)
+}
+
+func (e *batchExternal) Create(ctx context.Context, mg xpresource.Managed) (managed.ExternalCreation, error) {
+	e.batcher.Invalidate(mg.GetUID())
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	return ec.Create(ctx, mg)
+}
+
+func (e *batchExternal) Update(ctx context.Context, mg xpresource.Managed) (managed.ExternalUpdate, error) {
+	e.batcher.Invalidate(mg.GetUID())
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	return ec.Update(ctx, mg)
+}
+
+func (e *batchExternal) Delete(ctx context.Context, mg xpresource.Managed) error {
+	e.batcher.Forget(mg.GetUID())
+	ec, err := e.connect(ctx, mg)
+	if err != nil {
+		return err
+	}
+	return ec.Delete(ctx, mg)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"k8s.io/utils/exec"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/render"
+)
+
+// FileOutput is the file in the directory of a workspace that holds the
+// tails of the output of the last Terraform commands run in it, with Vault
+// tokens redacted.
+const FileOutput = "terraform-output.log"
+
+const (
+	// outputTail is the number of bytes kept of the output of a command.
+	outputTail = 8 << 10
+	// outputCommands is the number of commands whose output is kept per
+	// workspace.
+	outputCommands = 5
+)
+
+// An output is the tail of the output of a command.
+type output struct {
+	op   Operation
+	tail []byte
+}
+
+// record keeps the tail of the supplied output of the supplied operation, and
+// writes the tails of the last commands of the workspace to its FileOutput
+// so that they are available to support bundles, which are collected by
+// another process.
+func (e *entry) record(op *Operation, out []byte) {
+	// Tokens are redacted before the output is cut so that none is cut in
+	// half.
+	out = render.RedactTokens(out)
+	if len(out) > outputTail {
+		out = out[len(out)-outputTail:]
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.outputs = append(e.outputs, output{op: *op, tail: out})
+	if len(e.outputs) > outputCommands {
+		e.outputs = e.outputs[len(e.outputs)-outputCommands:]
+	}
+	b := &bytes.Buffer{}
+	for _, o := range e.outputs {
+		result := o.op.Error
+		if o.op.ExitStatus != nil {
+			result = fmt.Sprintf("exit status %d", *o.op.ExitStatus)
+		}
+		fmt.Fprintf(b, "$ terraform %s\n# started %s, took %s, %s\n", o.op.Command, o.op.StartTime.Format(time.RFC3339), o.op.Duration.Duration, result)
+		b.Write(o.tail)
+		if len(o.tail) > 0 && o.tail[len(o.tail)-1] != '\n' {
+			b.WriteByte('\n')
+		}
+	}
+	// The workspace may have been removed in the meantime, in which case
+	// there is nothing to write the output to.
+	_ = ioutil.WriteFile(filepath.Join(e.status.Directory, FileOutput), b.Bytes(), 0600)
+}
+
+// executor records the commands it runs as operations of a workspace.
+type executor struct {
+	exec.Interface
+	entry *entry
+}
+
+func (e *executor) Command(cmd string, args ...string) exec.Cmd {
+	return &recordedCmd{Cmd: e.Interface.Command(cmd, args...), entry: e.entry, command: command(args)}
+}
+
+func (e *executor) CommandContext(ctx context.Context, cmd string, args ...string) exec.Cmd {
+	return &recordedCmd{Cmd: e.Interface.CommandContext(ctx, cmd, args...), entry: e.entry, command: command(args)}
+}
+
+// recordedCmd records the run of a command from its start to its end.
+type recordedCmd struct {
+	exec.Cmd
+	entry   *entry
+	command string
+	op      *Operation
+}
+
+func (c *recordedCmd) Run() error {
+	op := c.entry.start(c.command)
+	err := c.Cmd.Run()
+	c.entry.end(op, err)
+	return err
+}
+
+func (c *recordedCmd) CombinedOutput() ([]byte, error) {
+	op := c.entry.start(c.command)
+	out, err := c.Cmd.CombinedOutput()
+	c.entry.end(op, err)
+	c.entry.record(op, out)
+	return out, err
+}
+
+func (c *recordedCmd) Output() ([]byte, error) {
+	op := c.entry.start(c.command)
+	out, err := c.Cmd.Output()
+	c.entry.end(op, err)
+	c.entry.record(op, out)
+	return out, err
+}
+
+func (c *recordedCmd) Start() error {
+	c.op = c.entry.start(c.command)
+	err := c.Cmd.Start()
+	if err != nil {
+		c.entry.end(c.op, err)
+		c.op = nil
+	}
+	return err
+}
+
+func (c *recordedCmd) Wait() error {
+	err := c.Cmd.Wait()
+	if c.op != nil {
+		c.entry.end(c.op, err)
+		c.op = nil
+	}
+	return err
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/utils/exec"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	errWaitProcess = "cannot wait for a Terraform process slot"
+	errFmtTimeout  = "Terraform command was killed after %s"
+)
+
+var (
+	metricQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "terraform_pool",
+		Name:      "queue_depth",
+		Help:      "Number of Terraform commands waiting for a process slot.",
+	})
+	metricRunning = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "terraform_pool",
+		Name:      "running_processes",
+		Help:      "Number of Terraform commands that are running.",
+	})
+	metricMemory = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "terraform_pool",
+		Name:      "memory_bytes",
+		Help:      "Estimated memory of the Terraform commands that are running.",
+	})
+	metricWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "terraform_pool",
+		Name:      "wait_seconds",
+		Help:      "Time Terraform commands waited for a process slot.",
+		Buckets:   []float64{0.01, 0.1, 0.5, 1, 5, 15, 30, 60, 120, 300},
+	})
+	metricTimeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "provider_jet_vault",
+		Subsystem: "terraform_pool",
+		Name:      "timeouts_total",
+		Help:      "Number of Terraform commands that were killed because they timed out.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricQueueDepth, metricRunning, metricMemory, metricWait, metricTimeouts)
+}
+
+// PoolOptions configure the Terraform process pool.
+type PoolOptions struct {
+	// MaxProcesses is the number of Terraform commands that may run at the
+	// same time. There is no limit if it is 0.
+	MaxProcesses int
+	// MemoryBudget is the memory, in bytes, the Terraform commands that run
+	// at the same time may use together. There is no budget if it is 0.
+	MemoryBudget int64
+	// ProcessMemory is the estimated memory, in bytes, of a Terraform
+	// command and the provider plugin it runs.
+	ProcessMemory int64
+	// Timeout is the time after which a Terraform command is killed, together
+	// with the processes it started if the executor runs commands in process
+	// groups of their own. Commands do not time out if it is 0.
+	Timeout time.Duration
+}
+
+// waiter is a Terraform command that waits for a process slot.
+type waiter struct {
+	ready    chan struct{}
+	granted  bool
+	enqueued time.Time
+}
+
+// A Pool bounds the Terraform commands that run at the same time by their
+// number and their estimated memory. Commands that have to wait are queued by
+// the ProviderConfig of their workspace, and the queues are served in turn so
+// that a burst of one ProviderConfig does not hold up the others.
+type Pool struct {
+	opts PoolOptions
+
+	mu      sync.Mutex
+	running int
+	memory  int64
+	queues  map[string][]*waiter
+	// order are the ProviderConfigs that have queued commands, in the order
+	// they are served in.
+	order []string
+}
+
+// EnablePool makes the Store run the Terraform commands of its workspaces
+// through a process pool with the supplied options.
+func (s *Store) EnablePool(o PoolOptions) *Pool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pool = &Pool{opts: o, queues: map[string][]*waiter{}}
+	return s.pool
+}
+
+// Pool returns the process pool of the Store, or nil if it has none.
+func (s *Store) Pool() *Pool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pool
+}
+
+// Acquire waits until a Terraform command of the supplied ProviderConfig may
+// run. The returned function has to be called when the command ended. A nil
+// Pool lets every command run.
+func (p *Pool) Acquire(ctx context.Context, providerConfig string) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+	p.mu.Lock()
+	if len(p.order) == 0 && p.admits() {
+		p.take()
+		p.mu.Unlock()
+		metricWait.Observe(0)
+		return p.release, nil
+	}
+	w := &waiter{ready: make(chan struct{}), enqueued: time.Now()}
+	if len(p.queues[providerConfig]) == 0 {
+		p.order = append(p.order, providerConfig)
+	}
+	p.queues[providerConfig] = append(p.queues[providerConfig], w)
+	metricQueueDepth.Inc()
+	p.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return p.release, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if w.granted {
+			p.put()
+			p.dispatch()
+			return nil, errors.Wrap(ctx.Err(), errWaitProcess)
+		}
+		p.dequeue(providerConfig, w)
+		metricQueueDepth.Dec()
+		return nil, errors.Wrap(ctx.Err(), errWaitProcess)
+	}
+}
+
+func (p *Pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.put()
+	p.dispatch()
+}
+
+// admits returns true if another command may run. A single command always
+// may, even if its estimated memory exceeds the budget.
+func (p *Pool) admits() bool {
+	if p.opts.MaxProcesses > 0 && p.running >= p.opts.MaxProcesses {
+		return false
+	}
+	if p.opts.MemoryBudget > 0 && p.running > 0 && p.memory+p.opts.ProcessMemory > p.opts.MemoryBudget {
+		return false
+	}
+	return true
+}
+
+func (p *Pool) take() {
+	p.running++
+	p.memory += p.opts.ProcessMemory
+	metricRunning.Inc()
+	metricMemory.Add(float64(p.opts.ProcessMemory))
+}
+
+func (p *Pool) put() {
+	p.running--
+	p.memory -= p.opts.ProcessMemory
+	metricRunning.Dec()
+	metricMemory.Sub(float64(p.opts.ProcessMemory))
+}
+
+// dispatch lets the queued commands run while the pool admits them, taking
+// one from the queue of each ProviderConfig in turn.
+func (p *Pool) dispatch() {
+	for len(p.order) > 0 && p.admits() {
+		pc := p.order[0]
+		p.order = p.order[1:]
+		q := p.queues[pc]
+		w := q[0]
+		if len(q) == 1 {
+			delete(p.queues, pc)
+		} else {
+			p.queues[pc] = q[1:]
+			p.order = append(p.order, pc)
+		}
+		p.take()
+		w.granted = true
+		close(w.ready)
+		metricQueueDepth.Dec()
+		metricWait.Observe(time.Since(w.enqueued).Seconds())
+	}
+}
+
+func (p *Pool) dequeue(providerConfig string, w *waiter) {
+	q := p.queues[providerConfig]
+	for i := range q {
+		if q[i] != w {
+			continue
+		}
+		q = append(q[:i], q[i+1:]...)
+		break
+	}
+	if len(q) > 0 {
+		p.queues[providerConfig] = q
+		return
+	}
+	delete(p.queues, providerConfig)
+	for i := range p.order {
+		if p.order[i] == providerConfig {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// executor returns an executor that runs the commands of the supplied
+// executor through the pool. A nil Pool returns the supplied executor.
+func (p *Pool) executor(e exec.Interface, providerConfig func() string) exec.Interface {
+	if p == nil {
+		return e
+	}
+	return &pooledExecutor{Interface: e, pool: p, providerConfig: providerConfig}
+}
+
+// pooledExecutor runs commands once the pool admits them, and kills them when
+// they time out.
+type pooledExecutor struct {
+	exec.Interface
+	pool           *Pool
+	providerConfig func() string
+}
+
+func (e *pooledExecutor) Command(cmd string, args ...string) exec.Cmd {
+	return e.CommandContext(context.Background(), cmd, args...)
+}
+
+func (e *pooledExecutor) CommandContext(ctx context.Context, cmd string, args ...string) exec.Cmd {
+	cctx, cancel := context.WithCancel(ctx)
+	return &pooledCmd{
+		Cmd:            e.Interface.CommandContext(cctx, cmd, args...),
+		ctx:            ctx,
+		cancel:         cancel,
+		pool:           e.pool,
+		providerConfig: e.providerConfig(),
+	}
+}
+
+// pooledCmd is a command that waits for a process slot before it starts.
+// Cancelling the context it was created with kills its process.
+type pooledCmd struct {
+	exec.Cmd
+	ctx            context.Context
+	cancel         context.CancelFunc
+	pool           *Pool
+	providerConfig string
+
+	release func()
+	timer   *time.Timer
+
+	mu       sync.Mutex
+	timedOut bool
+}
+
+func (c *pooledCmd) start() error {
+	release, err := c.pool.Acquire(c.ctx, c.providerConfig)
+	if err != nil {
+		c.cancel()
+		return err
+	}
+	c.release = release
+	if t := c.pool.opts.Timeout; t > 0 {
+		c.timer = time.AfterFunc(t, func() {
+			c.mu.Lock()
+			c.timedOut = true
+			c.mu.Unlock()
+			metricTimeouts.Inc()
+			c.cancel()
+		})
+	}
+	return nil
+}
+
+func (c *pooledCmd) end(err error) error {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.cancel()
+	if c.release != nil {
+		c.release()
+		c.release = nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil && c.timedOut {
+		return errors.Wrapf(err, errFmtTimeout, c.pool.opts.Timeout)
+	}
+	return err
+}
+
+func (c *pooledCmd) Run() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	return c.end(c.Cmd.Run())
+}
+
+func (c *pooledCmd) CombinedOutput() ([]byte, error) {
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	out, err := c.Cmd.CombinedOutput()
+	return out, c.end(err)
+}
+
+func (c *pooledCmd) Output() ([]byte, error) {
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	out, err := c.Cmd.Output()
+	return out, c.end(err)
+}
+
+func (c *pooledCmd) Start() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	if err := c.Cmd.Start(); err != nil {
+		return c.end(err)
+	}
+	return nil
+}
+
+func (c *pooledCmd) Wait() error {
+	return c.end(c.Cmd.Wait())
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspace
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/procgroup"
+)
+
+// waitQueued waits until the supplied pool has the supplied number of
+// queued commands.
+func waitQueued(t *testing.T, p *Pool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p.mu.Lock()
+		queued := 0
+		for _, q := range p.queues {
+			queued += len(q)
+		}
+		p.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("waitQueued(...): %d commands queued, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// acquired reports whether Acquire returns within a short time.
+func acquired(p *Pool, providerConfig string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := p.Acquire(ctx, providerConfig)
+	return err == nil
+}
+
+func TestAcquire(t *testing.T) {
+	cases := map[string]struct {
+		opts    PoolOptions
+		running int
+		memory  int64
+		want    bool
+	}{
+		"Unbounded": {
+			opts:    PoolOptions{},
+			running: 10,
+			want:    true,
+		},
+		"BelowMaxProcesses": {
+			opts:    PoolOptions{MaxProcesses: 2},
+			running: 1,
+			want:    true,
+		},
+		"AtMaxProcesses": {
+			opts:    PoolOptions{MaxProcesses: 2},
+			running: 2,
+			want:    false,
+		},
+		"WithinMemoryBudget": {
+			opts:    PoolOptions{MemoryBudget: 300, ProcessMemory: 100},
+			running: 2,
+			memory:  200,
+			want:    true,
+		},
+		"ExceedsMemoryBudget": {
+			opts:    PoolOptions{MemoryBudget: 300, ProcessMemory: 100},
+			running: 3,
+			memory:  300,
+			want:    false,
+		},
+		"SingleCommandExceedsMemoryBudget": {
+			opts: PoolOptions{MemoryBudget: 100, ProcessMemory: 200},
+			want: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := &Pool{opts: tc.opts, queues: map[string][]*waiter{}, running: tc.running, memory: tc.memory}
+			if got := acquired(p, "default"); got != tc.want {
+				t.Errorf("Acquire(...): acquired %t, want %t", got, tc.want)
+			}
+			if len(p.queues) != 0 || len(p.order) != 0 {
+				t.Errorf("Acquire(...): queues %v and order %v left behind", p.queues, p.order)
+			}
+		})
+	}
+}
+
+func TestAcquireNilPool(t *testing.T) {
+	var p *Pool
+	release, err := p.Acquire(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("Acquire(...): %s", err)
+	}
+	release()
+}
+
+func TestDispatchFairness(t *testing.T) {
+	p := &Pool{opts: PoolOptions{MaxProcesses: 1}, queues: map[string][]*waiter{}}
+	release, err := p.Acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Acquire(...): %s", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	releases := make(chan func(), 4)
+	queued := 0
+	for _, cmd := range []struct{ providerConfig, name string }{
+		{"a", "a1"}, {"a", "a2"}, {"a", "a3"}, {"b", "b1"},
+	} {
+		cmd := cmd
+		go func() {
+			r, err := p.Acquire(context.Background(), cmd.providerConfig)
+			if err != nil {
+				t.Errorf("Acquire(...): %s", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, cmd.name)
+			mu.Unlock()
+			releases <- r
+		}()
+		queued++
+		waitQueued(t, p, queued)
+	}
+
+	release()
+	for i := 0; i < 4; i++ {
+		(<-releases)()
+	}
+	want := []string{"a1", "b1", "a2", "a3"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Acquire(...): commands ran in order %v, want %v", order, want)
+	}
+	if p.running != 0 || len(p.queues) != 0 || len(p.order) != 0 {
+		t.Errorf("Acquire(...): %d running and queues %v left behind", p.running, p.queues)
+	}
+}
+
+func TestDispatchMemoryBudget(t *testing.T) {
+	p := &Pool{opts: PoolOptions{MemoryBudget: 200, ProcessMemory: 100}, queues: map[string][]*waiter{}}
+	r1, _ := p.Acquire(context.Background(), "a")
+	r2, _ := p.Acquire(context.Background(), "a")
+
+	granted := make(chan func(), 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			r, err := p.Acquire(context.Background(), "b")
+			if err != nil {
+				t.Errorf("Acquire(...): %s", err)
+				return
+			}
+			granted <- r
+		}()
+		waitQueued(t, p, i+1)
+	}
+
+	// Releasing one command frees the memory of one other.
+	r1()
+	r3 := <-granted
+	waitQueued(t, p, 1)
+	r2()
+	r4 := <-granted
+	waitQueued(t, p, 0)
+	r3()
+	r4()
+	if p.running != 0 || p.memory != 0 {
+		t.Errorf("Acquire(...): %d running with %d bytes left behind", p.running, p.memory)
+	}
+}
+
+func TestDequeue(t *testing.T) {
+	cases := map[string]struct {
+		queues    map[string][]string
+		order     []string
+		pc        string
+		remove    string
+		wantQueue map[string][]string
+		wantOrder []string
+	}{
+		"RemovesFromQueue": {
+			queues:    map[string][]string{"a": {"a1", "a2"}, "b": {"b1"}},
+			order:     []string{"a", "b"},
+			pc:        "a",
+			remove:    "a1",
+			wantQueue: map[string][]string{"a": {"a2"}, "b": {"b1"}},
+			wantOrder: []string{"a", "b"},
+		},
+		"RemovesEmptyQueueFromOrder": {
+			queues:    map[string][]string{"a": {"a1"}, "b": {"b1"}},
+			order:     []string{"a", "b"},
+			pc:        "a",
+			remove:    "a1",
+			wantQueue: map[string][]string{"b": {"b1"}},
+			wantOrder: []string{"b"},
+		},
+		"UnknownWaiter": {
+			queues:    map[string][]string{"a": {"a1"}},
+			order:     []string{"a"},
+			pc:        "a",
+			remove:    "a2",
+			wantQueue: map[string][]string{"a": {"a1"}},
+			wantOrder: []string{"a"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			waiters := map[string]*waiter{}
+			names := map[*waiter]string{}
+			p := &Pool{queues: map[string][]*waiter{}, order: tc.order}
+			for pc, q := range tc.queues {
+				for _, n := range q {
+					w := &waiter{}
+					waiters[n], names[w] = w, n
+					p.queues[pc] = append(p.queues[pc], w)
+				}
+			}
+			w, ok := waiters[tc.remove]
+			if !ok {
+				w = &waiter{}
+			}
+			p.dequeue(tc.pc, w)
+
+			got := map[string][]string{}
+			for pc, q := range p.queues {
+				for _, w := range q {
+					got[pc] = append(got[pc], names[w])
+				}
+			}
+			if !reflect.DeepEqual(got, tc.wantQueue) {
+				t.Errorf("dequeue(...): queues %v, want %v", got, tc.wantQueue)
+			}
+			if !reflect.DeepEqual(p.order, tc.wantOrder) {
+				t.Errorf("dequeue(...): order %v, want %v", p.order, tc.wantOrder)
+			}
+		})
+	}
+}
+
+func TestAcquireCancelled(t *testing.T) {
+	p := &Pool{opts: PoolOptions{MaxProcesses: 1}, queues: map[string][]*waiter{}}
+	release, _ := p.Acquire(context.Background(), "a")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := p.Acquire(ctx, "b")
+		errs <- err
+	}()
+	waitQueued(t, p, 1)
+	cancel()
+	if err := <-errs; err == nil {
+		t.Fatal("Acquire(...): want an error for a cancelled context")
+	}
+	if len(p.queues) != 0 || len(p.order) != 0 {
+		t.Errorf("Acquire(...): cancelled command left queues %v and order %v behind", p.queues, p.order)
+	}
+
+	// The slot of the first command is handed to the next one, not to the
+	// cancelled one.
+	release()
+	if !acquired(p, "c") {
+		t.Error("Acquire(...): slot not available after release")
+	}
+}
+
+func TestPooledCommandTimeout(t *testing.T) {
+	p := &Pool{opts: PoolOptions{MaxProcesses: 1, Timeout: 100 * time.Millisecond}, queues: map[string][]*waiter{}}
+	run := p.executor(procgroup.New(), func() string { return "default" })
+
+	start := time.Now()
+	_, err := run.CommandContext(context.Background(), "sleep", "10").CombinedOutput()
+	if err == nil || !strings.Contains(err.Error(), "killed after") {
+		t.Fatalf("CombinedOutput(): error %v, want a timeout", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("CombinedOutput(): returned after %s", d)
+	}
+	if p.running != 0 {
+		t.Errorf("CombinedOutput(): %d commands left running", p.running)
+	}
+}
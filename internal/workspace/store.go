@@ -0,0 +1,340 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workspace keeps track of the Terraform workspaces of the managed
+// resources and of the Terraform commands run in them. It can also observe
+// the resources in batched workspaces that are shared by resources of the
+// same kind and ProviderConfig, and bound the Terraform processes that run at
+// the same time.
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	xpresource "github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/config"
+	"github.com/crossplane/terrajet/pkg/resource"
+	"github.com/crossplane/terrajet/pkg/terraform"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/exec"
+
+	"github.com/crossplane-contrib/provider-jet-vault/internal/procgroup"
+	"github.com/crossplane-contrib/provider-jet-vault/internal/sandbox"
+)
+
+const (
+	fileLock = ".terraform.lock.hcl"
+
+	errMkdirWorkspace = "cannot create directory for workspace"
+	errFileProducer   = "cannot create a new file producer"
+	errWriteMainTF    = "cannot write main tf file"
+	errInitWorkspace  = "cannot init workspace"
+)
+
+var (
+	storesMu sync.Mutex
+	stores   = map[*terraform.WorkspaceStore]*Store{}
+)
+
+// Wrap returns the Store that tracks the workspaces of the supplied Terrajet
+// workspace store. The same Store is returned for the same Terrajet store so
+// that the controllers, which are only given the Terrajet store in their
+// options, and the callers listing the workspaces share it.
+func Wrap(ws *terraform.WorkspaceStore) *Store {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	s, ok := stores[ws]
+	if !ok {
+		s = &Store{
+			store:    ws,
+			executor: procgroup.New(),
+			entries:  map[types.UID]*entry{},
+		}
+		stores[ws] = s
+	}
+	return s
+}
+
+// An Operation is a Terraform command run in a workspace.
+type Operation struct {
+	// Command is the Terraform command with its arguments, e.g.
+	// apply -refresh-only -auto-approve -input=false -lock=false -json
+	Command string `json:"command"`
+	// StartTime is the time the command was started at.
+	StartTime metav1.Time `json:"startTime"`
+	// Duration is how long the command ran, or has been running for if it is
+	// still in progress.
+	Duration metav1.Duration `json:"duration"`
+	// ExitStatus is the exit status of the command. It is not set for
+	// commands that are in progress or that could not be run.
+	ExitStatus *int `json:"exitStatus,omitempty"`
+	// Error is the error the command failed with, if any.
+	Error string `json:"error,omitempty"`
+
+	end time.Time
+}
+
+func (o *Operation) finish(err error) {
+	o.end = time.Now()
+	o.Duration = metav1.Duration{Duration: o.end.Sub(o.StartTime.Time)}
+	if err == nil {
+		code := 0
+		o.ExitStatus = &code
+		return
+	}
+	o.Error = err.Error()
+	if ee, ok := errors.Cause(err).(exec.ExitError); ok && ee.Exited() {
+		code := ee.ExitStatus()
+		o.ExitStatus = &code
+	}
+}
+
+// A Status is the state of the workspace of a managed resource.
+type Status struct {
+	// UID is the UID of the managed resource, which names its workspace.
+	UID types.UID `json:"uid"`
+	// Kind and Name identify the managed resource.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// ProviderConfig is the name of the ProviderConfig of the managed
+	// resource.
+	ProviderConfig string `json:"providerConfig,omitempty"`
+	// Directory is the directory of the workspace.
+	Directory string `json:"directory"`
+	// LastOperation is the last command that ended in the workspace.
+	LastOperation *Operation `json:"lastOperation,omitempty"`
+	// InProgress are the commands that are running in the workspace.
+	InProgress []Operation `json:"inProgress,omitempty"`
+	// Members are the names of the managed resources observed in a batched
+	// workspace, which has no Name.
+	Members []string `json:"members,omitempty"`
+}
+
+type entry struct {
+	mu        sync.Mutex
+	status    Status
+	running   map[*Operation]struct{}
+	outputs   []output
+	workspace *terraform.Workspace
+}
+
+func (e *entry) start(command string) *Operation {
+	op := &Operation{Command: command, StartTime: metav1.Now()}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.running[op] = struct{}{}
+	return op
+}
+
+func (e *entry) end(op *Operation, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	op.finish(err)
+	delete(e.running, op)
+	if e.status.LastOperation == nil || !op.end.Before(e.status.LastOperation.end) {
+		e.status.LastOperation = op
+	}
+}
+
+func (e *entry) providerConfig() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status.ProviderConfig
+}
+
+func (e *entry) snapshot(now time.Time) Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.status
+	if s.LastOperation != nil {
+		op := *s.LastOperation
+		s.LastOperation = &op
+	}
+	for op := range e.running {
+		c := *op
+		c.Duration = metav1.Duration{Duration: now.Sub(op.StartTime.Time)}
+		s.InProgress = append(s.InProgress, c)
+	}
+	sort.Slice(s.InProgress, func(i, j int) bool {
+		return s.InProgress[i].StartTime.Before(&s.InProgress[j].StartTime)
+	})
+	return s
+}
+
+// A Store is a Terrajet workspace store that records the managed resource
+// each workspace belongs to and the Terraform commands run in it.
+type Store struct {
+	store    *terraform.WorkspaceStore
+	executor exec.Interface
+
+	mu      sync.Mutex
+	entries map[types.UID]*entry
+	batcher *Batcher
+	pool    *Pool
+}
+
+// Workspace returns the workspace of the supplied managed resource from the
+// Terrajet store and makes sure the commands run in it are recorded.
+func (s *Store) Workspace(ctx context.Context, c resource.SecretClient, tr resource.Terraformed, ts terraform.Setup, cfg *config.Resource) (*terraform.Workspace, error) {
+	e := s.entry(tr)
+	run := s.executorOf(e)
+	if _, err := os.Stat(filepath.Join(e.status.Directory, fileLock)); os.IsNotExist(err) {
+		// The Terrajet store would run init with its own executor, so it is
+		// run with the one of the workspace before.
+		if err := initialize(ctx, run, c, tr, ts, cfg, e.status.Directory); err != nil {
+			return nil, err
+		}
+	}
+	w, err := s.store.Workspace(ctx, c, tr, ts, cfg)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// The executor is only replaced once per workspace, commands of
+	// asynchronous operations may still be reading it.
+	if e.workspace != w {
+		terraform.WithExecutor(run)(w)
+		e.workspace = w
+	}
+	return w, nil
+}
+
+// EnableSandbox makes the Store run Terraform in a sandbox with the supplied
+// options.
+func (s *Store) EnableSandbox(o sandbox.Options) error {
+	e, err := sandbox.NewExecutor(o)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executor = e
+	return nil
+}
+
+// executorOf returns the executor that runs the commands of the workspace of
+// the supplied entry through the process pool and records them.
+func (s *Store) executorOf(e *entry) exec.Interface {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pool.executor(&executor{Interface: s.executor, entry: e}, e.providerConfig)
+}
+
+// initialize writes the configuration of the supplied managed resource to
+// the supplied workspace directory and runs init in it.
+func initialize(ctx context.Context, run exec.Interface, c resource.SecretClient, tr resource.Terraformed, ts terraform.Setup, cfg *config.Resource, dir string) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, errMkdirWorkspace)
+	}
+	fp, err := terraform.NewFileProducer(ctx, c, dir, tr, ts, cfg)
+	if err != nil {
+		return errors.Wrap(err, errFileProducer)
+	}
+	if err := fp.WriteMainTF(); err != nil {
+		return errors.Wrap(err, errWriteMainTF)
+	}
+	cmd := run.CommandContext(ctx, "terraform", "init", "-input=false")
+	cmd.SetEnv(append(os.Environ(), ts.Env...))
+	cmd.SetDir(dir)
+	out, err := cmd.CombinedOutput()
+	return errors.Wrapf(err, "%s: %s", errInitWorkspace, out)
+}
+
+// Remove removes the workspace of the supplied object from the Terrajet
+// store and forgets about it.
+func (s *Store) Remove(obj xpresource.Object) error {
+	if err := s.store.Remove(obj); err != nil {
+		return err
+	}
+	if b := s.Batcher(); b != nil {
+		b.Forget(obj.GetUID())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, obj.GetUID())
+	return nil
+}
+
+// List returns the status of every workspace in the store, sorted by the
+// kind and name of their managed resources.
+func (s *Store) List() []Status {
+	s.mu.Lock()
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	result := make([]Status, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.snapshot(now))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (s *Store) entry(tr resource.Terraformed) *entry {
+	s.mu.Lock()
+	e, ok := s.entries[tr.GetUID()]
+	if !ok {
+		e = &entry{
+			status: Status{
+				UID:       tr.GetUID(),
+				Directory: filepath.Join(os.TempDir(), string(tr.GetUID())),
+			},
+			running: map[*Operation]struct{}{},
+		}
+		s.entries[tr.GetUID()] = e
+	}
+	s.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.status.Kind = kindOf(tr)
+	e.status.Name = tr.GetName()
+	e.status.ProviderConfig = ""
+	if ref := tr.GetProviderConfigReference(); ref != nil {
+		e.status.ProviderConfig = ref.Name
+	}
+	return e
+}
+
+// kindOf returns the kind of the supplied object. Objects read by typed
+// clients have no type meta so it is the name of their type.
+func kindOf(obj interface{}) string {
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// command returns the Terraform command recorded for the supplied arguments.
+func command(args []string) string {
+	return strings.Join(args, " ")
+}